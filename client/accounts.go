@@ -0,0 +1,34 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+)
+
+// GetAccountBalances fetches the on-chain balances of all the agents in one request
+func (c *WaspClient) GetAccountBalances(chainID coretypes.ChainID, agents []coretypes.AgentID) (map[coretypes.AgentID]map[balance.Color]int64, error) {
+	req := model.AccountBalancesRequest{AgentIDs: model.NewBytes(coretypes.EncodeAgentIDs(agents))}
+	res := &model.AccountBalancesResponse{}
+	if err := c.do(http.MethodPost, routes.AccountBalances(chainID.String()), req, res); err != nil {
+		return nil, err
+	}
+	ret := make(map[coretypes.AgentID]map[balance.Color]int64)
+	for agentID, bals := range res.Balances {
+		balances := make(map[balance.Color]int64)
+		for color, amount := range bals {
+			col, err := util.ColorFromString(string(color))
+			if err != nil {
+				return nil, fmt.Errorf("invalid color %s: %w", color, err)
+			}
+			balances[col] = amount
+		}
+		ret[agentID.AgentID()] = balances
+	}
+	return ret, nil
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountBalances(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	color := balance.Color{1}
+
+	e := echo.New()
+	e.POST(routes.AccountBalances(chainID.String()), func(c echo.Context) error {
+		var req model.AccountBalancesRequest
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		agentIDs, err := coretypes.DecodeAgentIDs(req.AgentIDs.Bytes())
+		if err != nil {
+			return echo.ErrBadRequest
+		}
+		res := model.AccountBalancesResponse{Balances: make(map[model.AgentID]map[model.Color]int64)}
+		for i := range agentIDs {
+			res.Balances[model.NewAgentID(&agentIDs[i])] = map[model.Color]int64{
+				model.NewColor(&balance.ColorIOTA): int64(i + 1),
+				model.NewColor(&color):             10,
+			}
+		}
+		return c.JSON(http.StatusOK, res)
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	agents := []coretypes.AgentID{coretypes.NewRandomAgentID(), coretypes.NewRandomAgentID()}
+	balances, err := NewWaspClient(srv.URL).GetAccountBalances(chainID, agents)
+	require.NoError(t, err)
+	require.Len(t, balances, 2)
+	require.EqualValues(t, map[balance.Color]int64{balance.ColorIOTA: 1, color: 10}, balances[agents[0]])
+	require.EqualValues(t, map[balance.Color]int64{balance.ColorIOTA: 2, color: 10}, balances[agents[1]])
+}
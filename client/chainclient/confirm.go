@@ -0,0 +1,276 @@
+package chainclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/apilib"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/subscribe"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// RequestParams are the parameters of the request transaction built by BuildRequest
+type RequestParams struct {
+	ContractHname coretypes.Hname
+	EntryPoint    coretypes.Hname
+	Args          requestargs.RequestArgs
+	Transfer      coretypes.ColoredBalances
+	Mint          map[address.Address]int64                 // new tokens minted by the transaction, optional
+	Sign          func(tx *sctransaction.Transaction) error // nil means signing with the SigScheme of the client
+}
+
+// BuildRequest builds and signs the request transaction without posting it, so its ID is known in advance
+func (c *Client) BuildRequest(par RequestParams) (*sctransaction.Transaction, error) {
+	return apilib.CreateRequestTransaction(c.requestTransactionParams(par))
+}
+
+// BuildRequestUnsigned builds the request transaction like BuildRequest, but leaves it unsigned. par.Sign is ignored
+func (c *Client) BuildRequestUnsigned(par RequestParams) (*sctransaction.Transaction, error) {
+	return apilib.BuildRequestTransaction(c.requestTransactionParams(par))
+}
+
+func (c *Client) requestTransactionParams(par RequestParams) apilib.CreateRequestTransactionParams {
+	return apilib.CreateRequestTransactionParams{
+		Level1Client:    c.Level1Client,
+		SenderSigScheme: c.SigScheme,
+		RequestSectionParams: []apilib.RequestSectionParams{{
+			TargetContractID: coretypes.NewContractID(c.ChainID, par.ContractHname),
+			EntryPointCode:   par.EntryPoint,
+			Transfer:         par.Transfer,
+			Args:             par.Args,
+		}},
+		Mint: par.Mint,
+		Sign: par.Sign,
+	}
+}
+
+// ErrOutcomeUnknown is matched by OutcomeUnknownError with errors.Is
+var ErrOutcomeUnknown = errors.New("outcome of posting the transaction is unknown")
+
+// OutcomeUnknownError is returned when the context is done while the transaction is being posted.
+// The level1 calls don't accept a context, so the transaction may still be posted in the background:
+// posting it again, or a new transaction for the same request, may execute the request twice.
+// Check the transaction with level1.IsConfirmed before retrying
+type OutcomeUnknownError struct {
+	TxID valuetransaction.ID
+	Err  error // the error of the context
+}
+
+func (e *OutcomeUnknownError) Error() string {
+	return fmt.Sprintf("transaction %s may have been posted, outcome unknown: %v", e.TxID.String(), e.Err)
+}
+
+func (e *OutcomeUnknownError) Unwrap() error {
+	return e.Err
+}
+
+func (e *OutcomeUnknownError) Is(target error) bool {
+	return target == ErrOutcomeUnknown
+}
+
+// PostCtx posts the transaction with post (e.g. Level1Client.PostTransaction), bounded by the context.
+// If the context is done before post returns, an OutcomeUnknownError is returned
+func PostCtx(ctx context.Context, tx *sctransaction.Transaction, post func(tx *valuetransaction.Transaction) error) error {
+	err := util.RunWithContext(ctx, func() error {
+		return post(tx.Transaction)
+	})
+	if err != nil && err == ctx.Err() {
+		return &OutcomeUnknownError{TxID: tx.ID(), Err: err}
+	}
+	return err
+}
+
+// ConfirmationStrategy is the way PostAndWaitForConfirmation finds out the request is processed
+type ConfirmationStrategy int
+
+const (
+	// ConfirmBoth waits for the ledger confirmation and for the 'request_out' event from the publishers
+	ConfirmBoth = ConfirmationStrategy(iota)
+	// ConfirmPoll waits for the ledger confirmation and polls the node until the request is processed.
+	// No publishers are needed
+	ConfirmPoll
+	// ConfirmSubscribe only waits for the 'request_out' event from the publishers
+	ConfirmSubscribe
+)
+
+// stages of PostAndWaitForConfirmation reported to ConfirmParams.OnProgress
+const (
+	ProgressPosted     = "posted"     // the transaction is posted to the ledger
+	ProgressConfirmed  = "confirmed"  // the transaction is confirmed by the ledger. Not reported with ConfirmSubscribe
+	ProgressRegistered = "registered" // the request is processed by the contract
+)
+
+// ConfirmParams are the parameters of PostAndWaitForConfirmation
+type ConfirmParams struct {
+	Confirmation ConfirmationStrategy // ConfirmBoth by default
+	// "host:port" of the publishers. Validated and deduplicated with subscribe.NewHostSet
+	PublisherHosts []string
+	// between 0 and the number of the deduplicated PublisherHosts
+	PublisherQuorum int
+	// Timeout bounds waiting for the request to be processed if the context has no deadline.
+	// model.WaitRequestProcessedDefaultTimeout if 0
+	Timeout time.Duration
+	// Event is the kind of the event published by the nodes when the request is processed.
+	// subscribe.EventRequestOut by default
+	Event string
+	// Subscribe opens the subscription to the publishers. subscribe.SubscribeMulti by default
+	Subscribe func(hosts []string, topics []string, quorum ...int) (*subscribe.Subscription, error)
+	// Unsubscribe closes the subscription opened with Subscribe. Subscription.Close by default
+	Unsubscribe func(subs *subscribe.Subscription)
+	// if not nil, it is called when each of the stages is reached (ProgressPosted etc). It must not block
+	OnProgress func(stage string)
+}
+
+// ConfirmTiming is the latency breakdown of PostAndWaitForConfirmation
+type ConfirmTiming struct {
+	// posting the transaction. It includes waiting for the ledger confirmation with ConfirmPoll and ConfirmBoth
+	Post time.Duration
+	// from the start of posting until the first 'request_out' event. 0 with ConfirmPoll
+	FirstEvent time.Duration
+	// from the start of posting until the request is confirmed as processed
+	Confirmation time.Duration
+}
+
+func (par *ConfirmParams) progress(stage string) {
+	if par.OnProgress != nil {
+		par.OnProgress(stage)
+	}
+}
+
+func (par *ConfirmParams) timeout() time.Duration {
+	if par.Timeout == 0 {
+		return model.WaitRequestProcessedDefaultTimeout
+	}
+	return par.Timeout
+}
+
+// remainingTime returns time left until the deadline of the context, or the default if there is no deadline
+func remainingTime(ctx context.Context, def time.Duration) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Until(deadline)
+	}
+	return def
+}
+
+// PostAndWaitForConfirmation posts the request transaction built by BuildRequest and waits until
+// its first request is processed, using the mechanism selected by par.Confirmation.
+// The context bounds all the calls. If it is done while the transaction is being posted, OutcomeUnknownError
+// is returned. The returned timing is filled as far as the call got
+func (c *Client) PostAndWaitForConfirmation(ctx context.Context, tx *sctransaction.Transaction, par ConfirmParams) (*ConfirmTiming, error) {
+	timing := &ConfirmTiming{}
+	switch par.Confirmation {
+	case ConfirmPoll:
+		postStart := time.Now()
+		if err := PostCtx(ctx, tx, c.Level1Client.PostAndWaitForConfirmation); err != nil {
+			return timing, err
+		}
+		timing.Post = time.Since(postStart)
+		par.progress(ProgressPosted)
+		par.progress(ProgressConfirmed)
+		err := util.RunWithContext(ctx, func() error {
+			return c.WaspClient.WaitUntilAllRequestsProcessed(tx, remainingTime(ctx, par.timeout()))
+		})
+		if err != nil {
+			return timing, err
+		}
+		timing.Confirmation = time.Since(postStart)
+		par.progress(ProgressRegistered)
+		return timing, nil
+
+	case ConfirmSubscribe, ConfirmBoth:
+		// The ID of the transaction is known after it is built, before it is posted.
+		// The subscription must be established and drained before posting: otherwise the 'request_out' event
+		// may be published before SubscribeMulti completes, or dropped while the subscription buffer is full
+		// during PostAndWaitForConfirmation, and WaitForPattern would time out
+		event := par.Event
+		if event == "" {
+			event = subscribe.EventRequestOut
+		}
+		pattern := subscribe.RequestOutPatternByID(c.ChainID.String(), tx.RequestID(0))
+		pattern[0] = event
+		subscribeFun := par.Subscribe
+		if subscribeFun == nil {
+			subscribeFun = subscribe.SubscribeMulti
+		}
+		hosts, err := subscribe.NewHostSet(par.PublisherHosts...)
+		if err != nil {
+			return timing, err
+		}
+		if err := hosts.CheckQuorum(par.PublisherQuorum); err != nil {
+			return timing, err
+		}
+		subs, err := subscribeFun(hosts, []string{event}, par.PublisherQuorum)
+		if err != nil {
+			return timing, err
+		}
+		if par.Unsubscribe != nil {
+			defer par.Unsubscribe(subs)
+		} else {
+			defer subs.Close()
+		}
+
+		postStart := time.Now()
+		var firstEvent time.Duration
+		processed := make(chan bool, 1)
+		waitTimeout := remainingTime(ctx, par.timeout())
+		go func() {
+			processed <- subs.WaitForPatternNotify(pattern, waitTimeout, func(*subscribe.HostMessage) {
+				if firstEvent == 0 {
+					firstEvent = time.Since(postStart)
+				}
+			}, par.PublisherQuorum)
+		}()
+
+		if par.Confirmation == ConfirmBoth {
+			if err := PostCtx(ctx, tx, c.Level1Client.PostAndWaitForConfirmation); err != nil {
+				return timing, err
+			}
+			par.progress(ProgressPosted)
+			par.progress(ProgressConfirmed)
+		} else {
+			if err := PostCtx(ctx, tx, c.Level1Client.PostTransaction); err != nil {
+				return timing, err
+			}
+			par.progress(ProgressPosted)
+		}
+		timing.Post = time.Since(postStart)
+		select {
+		case ok := <-processed:
+			if !ok {
+				return timing, fmt.Errorf("request was not processed in %v", waitTimeout)
+			}
+			timing.FirstEvent = firstEvent
+			timing.Confirmation = time.Since(postStart)
+			par.progress(ProgressRegistered)
+			return timing, nil
+		case <-ctx.Done():
+			return timing, ctx.Err()
+		}
+	}
+	return timing, fmt.Errorf("unknown confirmation strategy %d", par.Confirmation)
+}
+
+// PostRequestAndWait builds the request transaction, posts it and waits until the request is processed
+func (c *Client) PostRequestAndWait(ctx context.Context, req RequestParams, par ConfirmParams) (*sctransaction.Transaction, error) {
+	var tx *sctransaction.Transaction
+	err := util.RunWithContext(ctx, func() error {
+		var err error
+		tx, err = c.BuildRequest(req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.PostAndWaitForConfirmation(ctx, tx, par); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,130 @@
+package chainclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/client"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/subscribe"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, waspClient *client.WaspClient) (*Client, *testutil.UtxodbLevel1Client) {
+	level1Client := testutil.NewUtxodbLevel1Client()
+	c := New(level1Client, waspClient, coretypes.NewRandomChainID(), signaturescheme.RandBLS())
+	addr := c.SigScheme.Address()
+	require.NoError(t, level1Client.RequestFunds(&addr))
+	return c, level1Client
+}
+
+func TestPostAndWaitForConfirmationPoll(t *testing.T) {
+	timeouts := make(chan time.Duration, 1)
+	e := echo.New()
+	e.GET(routes.WaitRequestProcessed(":chainID", ":reqID"), func(c echo.Context) error {
+		var par model.WaitRequestProcessedParams
+		if err := c.Bind(&par); err != nil {
+			return err
+		}
+		timeouts <- par.Timeout
+		return c.NoContent(http.StatusOK)
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	c, level1Client := newTestClient(t, client.NewWaspClient(srv.URL))
+	tx, err := c.BuildRequest(RequestParams{ContractHname: coretypes.Hn("test"), EntryPoint: coretypes.Hn("test")})
+	require.NoError(t, err)
+
+	var stages []string
+	timing, err := c.PostAndWaitForConfirmation(context.Background(), tx, ConfirmParams{
+		Confirmation: ConfirmPoll,
+		OnProgress: func(stage string) {
+			stages = append(stages, stage)
+		},
+	})
+	require.NoError(t, err)
+	txid := tx.ID()
+	require.True(t, level1Client.UtxoDB.IsConfirmed(&txid))
+	require.Equal(t, []string{ProgressPosted, ProgressConfirmed, ProgressRegistered}, stages)
+	require.LessOrEqual(t, int64(timing.Post), int64(timing.Confirmation))
+	// the zero Timeout without a deadline of the context is the default
+	require.Equal(t, model.WaitRequestProcessedDefaultTimeout, <-timeouts)
+}
+
+func TestPostAndWaitForConfirmationInvalidQuorum(t *testing.T) {
+	c, level1Client := newTestClient(t, nil)
+	tx, err := c.BuildRequest(RequestParams{ContractHname: coretypes.Hn("test"), EntryPoint: coretypes.Hn("test")})
+	require.NoError(t, err)
+
+	_, err = c.PostAndWaitForConfirmation(context.Background(), tx, ConfirmParams{
+		Confirmation:    ConfirmSubscribe,
+		PublisherHosts:  []string{"a:1", "a:1", "b:1"},
+		PublisherQuorum: 3,
+		Subscribe: func([]string, []string, ...int) (*subscribe.Subscription, error) {
+			panic("subscribed with an invalid quorum")
+		},
+	})
+	require.Error(t, err)
+	// nothing is posted
+	txid := tx.ID()
+	require.False(t, level1Client.UtxoDB.IsConfirmed(&txid))
+}
+
+func TestPostRequestAndWait(t *testing.T) {
+	hosts := []string{"host1:5550"}
+	subs := subscribe.NewSubscription(hosts, []string{subscribe.EventRequestOut})
+
+	c, level1Client := newTestClient(t, nil)
+	// the request is processed well after the default timeout of a subscription
+	level1Client.OnPost = func(tx *valuetransaction.Transaction) {
+		go func() {
+			time.Sleep(300 * time.Millisecond)
+			subs.HostMessages <- &subscribe.HostMessage{
+				Sender:  hosts[0],
+				Message: append(subscribe.RequestOutPattern(c.ChainID.String(), tx.ID().String(), 0), "1", "0", "1"),
+			}
+		}()
+	}
+
+	tx, err := c.PostRequestAndWait(context.Background(), RequestParams{
+		ContractHname: coretypes.Hn("test"),
+		EntryPoint:    coretypes.Hn("test"),
+	}, ConfirmParams{
+		Confirmation:   ConfirmSubscribe,
+		PublisherHosts: hosts,
+		Subscribe: func([]string, []string, ...int) (*subscribe.Subscription, error) {
+			return subs, nil
+		},
+		Unsubscribe: func(*subscribe.Subscription) {},
+	})
+	require.NoError(t, err)
+	txid := tx.ID()
+	require.True(t, level1Client.UtxoDB.IsConfirmed(&txid))
+
+	// the context bounds the wait
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	level1Client.OnPost = nil
+	_, err = c.PostRequestAndWait(ctx, RequestParams{
+		ContractHname: coretypes.Hn("test"),
+		EntryPoint:    coretypes.Hn("test"),
+	}, ConfirmParams{
+		Confirmation:   ConfirmSubscribe,
+		PublisherHosts: hosts,
+		Subscribe: func([]string, []string, ...int) (*subscribe.Subscription, error) {
+			return subs, nil
+		},
+		Unsubscribe: func(*subscribe.Subscription) {},
+	})
+	require.Error(t, err)
+}
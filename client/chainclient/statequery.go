@@ -1,10 +1,17 @@
-// +build ignore
-
 package chainclient
 
-import "github.com/iotaledger/wasp/packages/webapi/model/statequery"
+import (
+	"context"
+
+	"github.com/iotaledger/wasp/packages/webapi/model/statequery"
+)
 
 // StateQuery queries the chain state, and returns the result of the query.
 func (c *Client) StateQuery(query *statequery.Request) (*statequery.Results, error) {
 	return c.WaspClient.StateQuery(&c.ChainID, query)
 }
+
+// StateQueryCtx is like StateQuery, but the query is canceled when the context is done
+func (c *Client) StateQueryCtx(ctx context.Context, query *statequery.Request) (*statequery.Results, error) {
+	return c.WaspClient.StateQueryCtx(ctx, &c.ChainID, query)
+}
@@ -1,8 +1,8 @@
-// +build ignore
-
 package chainclient
 
 import (
+	"context"
+	"fmt"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"time"
 
@@ -10,8 +10,7 @@ import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/wasp/packages/hashing"
-	"github.com/iotaledger/wasp/packages/txutil"
-	"github.com/iotaledger/wasp/packages/vm/vmconst"
+	"github.com/iotaledger/wasp/packages/util"
 	"github.com/iotaledger/wasp/packages/webapi/model/statequery"
 )
 
@@ -22,13 +21,9 @@ type SCStatus struct {
 	StateTxId  valuetransaction.ID
 	Requests   []*coretypes.RequestID
 
-	ProgramHash   *hashing.HashValue
-	Description   string
-	OwnerAddress  address.Address
-	SCAddress     address.Address
-	Balance       map[balance.Color]int64
-	MinimumReward int64
-	FetchedAt     time.Time
+	SCAddress address.Address
+	Balance   map[balance.Color]int64
+	FetchedAt time.Time
 }
 
 func (c *Client) FetchSCStatus(addCustomQueries func(query *statequery.Request)) (*SCStatus, *statequery.Results, error) {
@@ -36,23 +31,35 @@ func (c *Client) FetchSCStatus(addCustomQueries func(query *statequery.Request))
 	if err != nil {
 		return nil, nil, err
 	}
+	status, res, err := c.FetchSCStateCtx(context.Background(), addCustomQueries)
+	if err != nil {
+		return nil, nil, err
+	}
+	status.Balance = balance
+	return status, res, nil
+}
+
+// FetchSCState is like FetchSCStatus, but queries only the state of the chain. Balance is left nil
+func (c *Client) FetchSCState(addCustomQueries func(query *statequery.Request)) (*SCStatus, *statequery.Results, error) {
+	return c.FetchSCStateCtx(context.Background(), addCustomQueries)
+}
 
+// FetchSCStateCtx is like FetchSCState, but the state query is canceled when the context is done
+func (c *Client) FetchSCStateCtx(ctx context.Context, addCustomQueries func(query *statequery.Request)) (*SCStatus, *statequery.Results, error) {
 	query := statequery.NewRequest()
 	query.AddGeneralData()
-	query.AddScalar(vmconst.VarNameOwnerAddress)
-	query.AddScalar(vmconst.VarNameProgramData)
-	query.AddScalar(vmconst.VarNameDescription)
-	query.AddScalar(vmconst.VarNameMinimumReward)
 	addCustomQueries(query)
 
-	res, err := c.WaspClient.StateQuery(&c.ChainID, query)
+	res, err := c.WaspClient.StateQueryCtx(ctx, &c.ChainID, query)
 	if err != nil {
 		return nil, nil, err
 	}
+	return c.SCStatusFromResults(res), res, nil
+}
 
-	description, _ := res.Get(vmconst.VarNameDescription).MustString()
-	minReward, _ := res.Get(vmconst.VarNameMinimumReward).MustInt64()
-
+// SCStatusFromResults returns the status of the state from the results of a query with AddGeneralData.
+// Balance is left nil
+func (c *Client) SCStatusFromResults(res *statequery.Results) *SCStatus {
 	return &SCStatus{
 		StateIndex: res.StateIndex,
 		Timestamp:  res.Timestamp.UTC(),
@@ -60,22 +67,46 @@ func (c *Client) FetchSCStatus(addCustomQueries func(query *statequery.Request))
 		StateTxId:  res.StateTxId.ID(),
 		Requests:   res.Requests,
 
-		ProgramHash:   &res.Get(vmconst.VarNameProgramData).MustHashValue(),
-		Description:   description,
-		OwnerAddress:  res.Get(vmconst.VarNameOwnerAddress).MustAddress(),
-		MinimumReward: minReward,
-		SCAddress:     (address.Address)(c.ChainID),
-		Balance:       balance,
-		FetchedAt:     time.Now().UTC(),
-	}, res, nil
+		SCAddress: (address.Address)(c.ChainID),
+		FetchedAt: time.Now().UTC(),
+	}
 }
 
 func (c *Client) FetchBalance() (map[balance.Color]int64, error) {
+	return c.FetchBalanceCtx(context.Background())
+}
+
+// FetchBalanceCtx is like FetchBalance, but returns the error of the context if it is done before
+// the outputs are fetched
+func (c *Client) FetchBalanceCtx(ctx context.Context) (map[balance.Color]int64, error) {
 	addr := (address.Address)(c.ChainID)
-	outs, err := c.Level1Client.GetConfirmedAccountOutputs(&addr)
+	var outs map[valuetransaction.OutputID][]*balance.Balance
+	err := util.RunWithContext(ctx, func() error {
+		var err error
+		outs, err = c.Level1Client.GetConfirmedAccountOutputs(&addr)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	ret, _ := txutil.OutputBalancesByColor(outs)
+	return balancesByColor(outs)
+}
+
+// balancesByColor sums the balances of the outputs by color. Unlike txutil.OutputBalancesByColor,
+// it fails on the balances which can't come from the ledger instead of returning a wrong sum
+func balancesByColor(outs map[valuetransaction.OutputID][]*balance.Balance) (map[balance.Color]int64, error) {
+	ret := make(map[balance.Color]int64)
+	for outID, bals := range outs {
+		for _, b := range bals {
+			if b.Value <= 0 {
+				return nil, fmt.Errorf("invalid balance %d of color %s in output %s", b.Value, b.Color.String(), outID.String())
+			}
+			sum := ret[b.Color] + b.Value
+			if sum < 0 {
+				return nil, fmt.Errorf("overflow of the balance of color %s", b.Color.String())
+			}
+			ret[b.Color] = sum
+		}
+	}
 	return ret, nil
 }
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+)
+
+// stateIndexPollPeriod is the period of polling the chain info in WaitForStateIndex
+var stateIndexPollPeriod = 500 * time.Millisecond
+
+// GetChainInfo fetches the index of the last solid state of the chain
+func (c *WaspClient) GetChainInfo(chainID coretypes.ChainID) (*model.ChainInfo, error) {
+	return c.getChainInfo(context.Background(), chainID)
+}
+
+func (c *WaspClient) getChainInfo(ctx context.Context, chainID coretypes.ChainID) (*model.ChainInfo, error) {
+	res := &model.ChainInfo{}
+	if err := c.doCtx(ctx, http.MethodGet, routes.GetChainInfo(chainID.String()), nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WaitForStateIndex polls the chain info until the chain reaches the state index, e.g. to make tests deterministic
+// instead of sleeping. If the context expires first, the returned error matches the error of the context with errors.Is
+func (c *WaspClient) WaitForStateIndex(ctx context.Context, chainID coretypes.ChainID, index uint32) error {
+	ticker := time.NewTicker(stateIndexPollPeriod)
+	defer ticker.Stop()
+	for {
+		info, err := c.getChainInfo(ctx, chainID)
+		if err != nil {
+			return err
+		}
+		if info.HasState && info.StateIndex >= index {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForStateIndex(t *testing.T) {
+	oldPollPeriod := stateIndexPollPeriod
+	t.Cleanup(func() { stateIndexPollPeriod = oldPollPeriod })
+	stateIndexPollPeriod = 10 * time.Millisecond
+
+	chainID := coretypes.NewRandomChainID()
+	var polls uint32
+	e := echo.New()
+	e.GET(routes.GetChainInfo(chainID.String()), func(c echo.Context) error {
+		// the chain has no state on the first poll and advances by one state on each of the next ones
+		n := atomic.AddUint32(&polls, 1)
+		return c.JSON(http.StatusOK, &model.ChainInfo{
+			ChainID:    model.NewChainID(&chainID),
+			HasState:   n > 1,
+			StateIndex: n - 1,
+		})
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	c := NewWaspClient(srv.URL)
+	require.NoError(t, c.WaitForStateIndex(context.Background(), chainID, 3))
+	require.EqualValues(t, 4, atomic.LoadUint32(&polls))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.True(t, errors.Is(c.WaitForStateIndex(ctx, chainID, 1000), context.DeadlineExceeded))
+
+	require.Error(t, c.WaitForStateIndex(context.Background(), coretypes.NewRandomChainID(), 1))
+}
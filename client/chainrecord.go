@@ -1,6 +1,7 @@
 package client
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/iotaledger/wasp/packages/coretypes"
@@ -9,18 +10,37 @@ import (
 	"github.com/iotaledger/wasp/packages/webapi/routes"
 )
 
-// PutChainRecord sends a request to write a ChainRecord
+// PutChainRecord sends a request to write a ChainRecord. The weighted quorum configuration is validated first
 func (c *WaspClient) PutChainRecord(bd *registry.ChainRecord) error {
+	if err := bd.ValidateWeights(); err != nil {
+		return err
+	}
+	c.invalidateChainRecord(bd.ChainID)
 	return c.do(http.MethodPost, routes.PutChainRecord(), model.NewChainRecord(bd), nil)
 }
 
-// GetChainRecord fetches a ChainRecord by address
+// GetChainRecord fetches a ChainRecord by address. If the cache is enabled with WithChainRecordCache,
+// the cached record is returned
 func (c *WaspClient) GetChainRecord(chainid coretypes.ChainID) (*registry.ChainRecord, error) {
+	if c.chainRecords != nil {
+		if rec, ok := c.chainRecords.get(chainid); ok {
+			return rec, nil
+		}
+	}
+	return c.RefreshChainRecord(chainid)
+}
+
+// RefreshChainRecord fetches a ChainRecord from the node, bypassing the cache, and updates the cache
+func (c *WaspClient) RefreshChainRecord(chainid coretypes.ChainID) (*registry.ChainRecord, error) {
 	res := &model.ChainRecord{}
 	if err := c.do(http.MethodGet, routes.GetChainRecord(chainid.String()), nil, res); err != nil {
 		return nil, err
 	}
-	return res.ChainRecord(), nil
+	rec := res.ChainRecord()
+	if c.chainRecords != nil {
+		c.chainRecords.put(rec)
+	}
+	return rec, nil
 }
 
 // GetChainRecordList fetches the list of all chains in the node
@@ -35,3 +55,60 @@ func (c *WaspClient) GetChainRecordList() ([]*registry.ChainRecord, error) {
 	}
 	return list, nil
 }
+
+// DedupChainRecords removes records equal (see ChainRecord.Equals) to a preceding record in the list,
+// e.g. when aggregating chain records from several nodes
+func DedupChainRecords(records []*registry.ChainRecord) []*registry.ChainRecord {
+	ret := make([]*registry.ChainRecord, 0, len(records))
+	byChainID := make(map[coretypes.ChainID][]*registry.ChainRecord)
+	for _, rec := range records {
+		dup := false
+		for _, r := range byChainID[rec.ChainID] {
+			if r.Equals(rec) {
+				dup = true
+				break
+			}
+		}
+		if dup {
+			continue
+		}
+		byChainID[rec.ChainID] = append(byChainID[rec.ChainID], rec)
+		ret = append(ret, rec)
+	}
+	return ret
+}
+
+// CheckCommitteeMembers fetches the chain record and checks that all agents are owners of committee nodes.
+// The error of the first agent which is not is a *coretypes.AgentError
+func (c *WaspClient) CheckCommitteeMembers(chainid coretypes.ChainID, agents ...coretypes.AgentID) error {
+	rec, err := c.GetChainRecord(chainid)
+	if err != nil {
+		return err
+	}
+	for _, a := range agents {
+		if err := rec.CheckCommitteeMember(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateCommitteeRotation checks the chain record produced by ChainRecord.WithCommittee before
+// it is sent to the nodes: the ChainID must not change and the new committee must be able to reach
+// the quorum of the chain (the quorum is not part of the chain record, it is the threshold of the DKShare)
+func ValidateCommitteeRotation(original, rotated *registry.ChainRecord, quorum int) error {
+	if original.ChainID != rotated.ChainID {
+		return fmt.Errorf("committee rotation can't change the chain ID: %s -> %s", original.ChainID, rotated.ChainID)
+	}
+	if quorum <= 0 || quorum > len(rotated.CommitteeNodes) {
+		return fmt.Errorf("invalid quorum %d for committee of size %d", quorum, len(rotated.CommitteeNodes))
+	}
+	seen := make(map[string]bool)
+	for _, node := range rotated.CommitteeNodes {
+		if seen[node] {
+			return fmt.Errorf("duplicate committee node %s", node)
+		}
+		seen[node] = true
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupChainRecords(t *testing.T) {
+	rec1 := &registry.ChainRecord{
+		ChainID:        coretypes.NewRandomChainID(),
+		Color:          balance.Color{1},
+		CommitteeNodes: []string{"wasp1:4000", "wasp2:4000"},
+		Active:         true,
+	}
+	rec2 := &registry.ChainRecord{
+		ChainID:        coretypes.NewRandomChainID(),
+		Color:          balance.Color{2},
+		CommitteeNodes: []string{"wasp3:4000", "wasp4:4000"},
+	}
+	// same chain reported by another node, not active there
+	rec1Inactive := rec1.Clone()
+	rec1Inactive.Active = false
+	// near duplicate: same chain, different committee
+	rec1Rotated := rec1.WithCommittee([]string{"wasp1:4000", "wasp5:4000"})
+
+	deduped := DedupChainRecords([]*registry.ChainRecord{rec1, rec2, rec1.Clone(), rec1Inactive, rec1Rotated, rec2.Clone()})
+	require.EqualValues(t, []*registry.ChainRecord{rec1, rec2, rec1Rotated}, deduped)
+}
+
+func TestChainRecordCache(t *testing.T) {
+	rec := &registry.ChainRecord{
+		ChainID:        coretypes.NewRandomChainID(),
+		Color:          balance.Color{1},
+		CommitteeNodes: []string{"wasp1:4000"},
+	}
+	calls := 0
+	e := echo.New()
+	e.GET(routes.GetChainRecord(rec.ChainID.String()), func(c echo.Context) error {
+		calls++
+		return c.JSON(http.StatusOK, model.NewChainRecord(rec))
+	})
+	e.POST(routes.ActivateChain(rec.ChainID.String()), func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	c := NewWaspClient(srv.URL).WithChainRecordCache()
+	for i := 0; i < 3; i++ {
+		r, err := c.GetChainRecord(rec.ChainID)
+		require.NoError(t, err)
+		require.True(t, rec.Equals(r))
+		// changing the returned record doesn't change the cached one
+		r.CommitteeNodes[0] = "changed:4000"
+	}
+	require.EqualValues(t, 1, calls)
+
+	_, err := c.RefreshChainRecord(rec.ChainID)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, calls)
+
+	require.NoError(t, c.ActivateChain(rec.ChainID))
+	_, err = c.GetChainRecord(rec.ChainID)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, calls)
+
+	_, err = NewWaspClient(srv.URL).GetChainRecord(rec.ChainID)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, calls)
+}
+
+func TestSharedChainRecordCache(t *testing.T) {
+	rec := &registry.ChainRecord{
+		ChainID:        coretypes.NewRandomChainID(),
+		Color:          balance.Color{1},
+		CommitteeNodes: []string{"wasp1:4000"},
+	}
+	var calls int32
+	release := make(chan struct{})
+	loader := func(chainID coretypes.ChainID) (*registry.ChainRecord, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return rec.Clone(), nil
+	}
+
+	cache := NewChainRecordCache(time.Hour)
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r, err := cache.Get(rec.ChainID, loader)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			// changing the returned record doesn't change the cached one
+			r.CommitteeNodes[0] = "changed:4000"
+		}(i)
+	}
+	// let the goroutines pile up on the load in progress
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, 1, atomic.LoadInt32(&calls))
+
+	r, err := cache.Get(rec.ChainID, loader)
+	require.NoError(t, err)
+	require.True(t, rec.Equals(r))
+	require.EqualValues(t, 1, atomic.LoadInt32(&calls))
+
+	cache.Invalidate(rec.ChainID)
+	_, err = cache.Get(rec.ChainID, loader)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+	expiring := NewChainRecordCache(time.Millisecond)
+	_, err = expiring.Get(rec.ChainID, loader)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	_, err = expiring.Get(rec.ChainID, loader)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, atomic.LoadInt32(&calls))
+}
+
+func TestSharedChainRecordCacheFailedLoader(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	cache := NewChainRecordCache(time.Hour)
+
+	_, err := cache.Get(chainID, func(coretypes.ChainID) (*registry.ChainRecord, error) {
+		return nil, nil
+	})
+	require.Error(t, err)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		defer func() { recover() }()
+		_, _ = cache.Get(chainID, func(coretypes.ChainID) (*registry.ChainRecord, error) {
+			close(started)
+			<-release
+			panic("loader failed")
+		})
+	}()
+	<-started
+	waiting := make(chan error, 1)
+	go func() {
+		_, err := cache.Get(chainID, func(coretypes.ChainID) (*registry.ChainRecord, error) {
+			return nil, errors.New("not called while the load is in progress")
+		})
+		waiting <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	select {
+	case err := <-waiting:
+		require.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Get is blocked by the panicked load")
+	}
+
+	// the panicked load is not left in progress
+	rec := &registry.ChainRecord{ChainID: chainID, CommitteeNodes: []string{"wasp1:4000"}}
+	r, err := cache.Get(chainID, func(coretypes.ChainID) (*registry.ChainRecord, error) {
+		return rec.Clone(), nil
+	})
+	require.NoError(t, err)
+	require.True(t, rec.Equals(r))
+}
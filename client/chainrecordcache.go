@@ -0,0 +1,152 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/registry"
+)
+
+// chainRecordCache keeps the chain records fetched by GetChainRecord. The records are cloned in and out,
+// so callers can't change the cached ones
+type chainRecordCache struct {
+	mutex   sync.Mutex
+	records map[coretypes.ChainID]*registry.ChainRecord
+}
+
+func (c *chainRecordCache) get(chainID coretypes.ChainID) (*registry.ChainRecord, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	rec, ok := c.records[chainID]
+	if !ok {
+		return nil, false
+	}
+	return rec.Clone(), true
+}
+
+func (c *chainRecordCache) put(rec *registry.ChainRecord) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.records[rec.ChainID] = rec.Clone()
+}
+
+func (c *chainRecordCache) invalidate(chainID coretypes.ChainID) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.records, chainID)
+}
+
+// WithChainRecordCache makes GetChainRecord cache the fetched chain records. The cached record of a chain
+// is dropped when it is changed through the client (PutChainRecord, ActivateChain, DeactivateChain).
+// Changes made by others are not noticed until RefreshChainRecord is called
+func (c *WaspClient) WithChainRecordCache() *WaspClient {
+	c.chainRecords = &chainRecordCache{records: make(map[coretypes.ChainID]*registry.ChainRecord)}
+	return c
+}
+
+func (c *WaspClient) invalidateChainRecord(chainID coretypes.ChainID) {
+	if c.chainRecords != nil {
+		c.chainRecords.invalidate(chainID)
+	}
+}
+
+// ChainRecordLoader fetches the chain record from the node, e.g. WaspClient.RefreshChainRecord
+type ChainRecordLoader func(chainID coretypes.ChainID) (*registry.ChainRecord, error)
+
+// ChainRecordCache is a concurrency safe cache of chain records, which can be shared by many clients,
+// e.g. in a gateway. Concurrent Get calls of the same uncached chain wait for a single call of the loader.
+// The records are cloned in and out, so callers can't change the cached ones
+type ChainRecordCache struct {
+	ttl     time.Duration
+	mutex   sync.Mutex
+	entries map[coretypes.ChainID]*chainRecordEntry
+	loads   map[coretypes.ChainID]*chainRecordLoad
+}
+
+type chainRecordEntry struct {
+	rec     *registry.ChainRecord
+	fetched time.Time
+}
+
+// chainRecordLoad is the loader call in progress. done is closed when rec and err are set
+type chainRecordLoad struct {
+	done chan struct{}
+	rec  *registry.ChainRecord
+	err  error
+}
+
+// NewChainRecordCache creates the cache which keeps each record for ttl. 0 means records never expire
+func NewChainRecordCache(ttl time.Duration) *ChainRecordCache {
+	return &ChainRecordCache{
+		ttl:     ttl,
+		entries: make(map[coretypes.ChainID]*chainRecordEntry),
+		loads:   make(map[coretypes.ChainID]*chainRecordLoad),
+	}
+}
+
+// Get returns the cached record of the chain. If it is not cached or expired, the record is fetched by the loader.
+// If another goroutine is already loading the same chain, Get waits for its result instead of calling the loader.
+// Errors are not cached. A nil record returned by the loader is an error
+func (c *ChainRecordCache) Get(chainID coretypes.ChainID, loader ChainRecordLoader) (*registry.ChainRecord, error) {
+	c.mutex.Lock()
+	if e, ok := c.entries[chainID]; ok && (c.ttl == 0 || time.Since(e.fetched) < c.ttl) {
+		c.mutex.Unlock()
+		return e.rec.Clone(), nil
+	}
+	if load, ok := c.loads[chainID]; ok {
+		c.mutex.Unlock()
+		<-load.done
+		if load.err != nil {
+			return nil, load.err
+		}
+		return load.rec.Clone(), nil
+	}
+	load := &chainRecordLoad{done: make(chan struct{})}
+	c.loads[chainID] = load
+	c.mutex.Unlock()
+
+	return c.load(chainID, load, loader)
+}
+
+var errLoaderPanicked = errors.New("chain record loader panicked")
+
+// load calls the loader and passes the result to the Get calls waiting for it.
+// The load is finished even if the loader panics: the waiting calls get an error and the panic goes on
+func (c *ChainRecordCache) load(chainID coretypes.ChainID, load *chainRecordLoad, loader ChainRecordLoader) (*registry.ChainRecord, error) {
+	load.err = errLoaderPanicked
+	defer func() {
+		c.mutex.Lock()
+		delete(c.loads, chainID)
+		if load.err == nil {
+			c.entries[chainID] = &chainRecordEntry{rec: load.rec, fetched: time.Now()}
+		}
+		c.mutex.Unlock()
+		close(load.done)
+	}()
+
+	rec, err := loader(chainID)
+	if err == nil && rec == nil {
+		err = fmt.Errorf("chain record loader returned no record of chain %s", chainID.String())
+	}
+	if err != nil {
+		load.err = err
+		return nil, err
+	}
+	load.rec = rec.Clone()
+	load.err = nil
+	return load.rec.Clone(), nil
+}
+
+// Invalidate drops the cached record of the chain, e.g. after it was changed. A load in progress is not affected
+func (c *ChainRecordCache) Invalidate(chainID coretypes.ChainID) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.entries, chainID)
+}
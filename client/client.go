@@ -2,7 +2,10 @@ package client
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -16,9 +19,26 @@ import (
 type WaspClient struct {
 	httpClient http.Client
 	baseURL    string
+	headers    http.Header
+	// transport is created by NewWaspClient if no http.Client is given. Close only closes this one:
+	// the transport of a given client may be shared, e.g. http.DefaultTransport
+	transport *http.Transport
+
+	requestIDHeader    string
+	disableCompression bool
+	maxResponseSize    int64 // DefaultMaxResponseSize if 0
+	rateLimiter        *rateLimiter
+	chainRecords       *chainRecordCache // nil if not enabled with WithChainRecordCache
 }
 
+// DefaultMaxResponseSize is the limit of the size of a response body, unless changed with WithMaxResponseSize
+const DefaultMaxResponseSize = int64(64 * 1024 * 1024)
+
+// ErrResponseTooLarge is returned when the response body exceeds the limit set with WithMaxResponseSize
+var ErrResponseTooLarge = errors.New("response body is too large")
+
 // NewWaspClient returns a new *WaspClient with the given baseURL and httpClient.
+// Without httpClient, the client uses its own transport with the settings of http.DefaultTransport
 func NewWaspClient(baseURL string, httpClient ...http.Client) *WaspClient {
 	if !strings.Contains(baseURL, "://") {
 		baseURL = "http://" + baseURL
@@ -26,17 +46,68 @@ func NewWaspClient(baseURL string, httpClient ...http.Client) *WaspClient {
 	if len(httpClient) > 0 {
 		return &WaspClient{baseURL: baseURL, httpClient: httpClient[0]}
 	}
-	return &WaspClient{baseURL: baseURL}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	return &WaspClient{baseURL: baseURL, httpClient: http.Client{Transport: transport}, transport: transport}
 }
 
-func processResponse(res *http.Response, decodeTo interface{}) error {
-	resBody, err := ioutil.ReadAll(res.Body)
+// WithHeader sets a header to be sent with every request
+func (c *WaspClient) WithHeader(key, value string) *WaspClient {
+	if c.headers == nil {
+		c.headers = make(http.Header)
+	}
+	c.headers.Set(key, value)
+	return c
+}
+
+// WithBearerToken sets the token to be sent in the Authorization header of every request
+func (c *WaspClient) WithBearerToken(token string) *WaspClient {
+	return c.WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithoutCompression disables gzip encoding of responses, e.g. when a proxy between the
+// client and the node mishandles the Content-Encoding header
+func (c *WaspClient) WithoutCompression() *WaspClient {
+	c.disableCompression = true
+	return c
+}
+
+// WithMaxResponseSize limits the size of a response body read into memory, after decompression,
+// e.g. to protect the client from untrusted nodes. Streamed responses are not limited
+func (c *WaspClient) WithMaxResponseSize(n int64) *WaspClient {
+	c.maxResponseSize = n
+	return c
+}
+
+// processResponse decodes the JSON response into decodeTo. If decodeTo is an io.Writer, the body is copied to it instead.
+// Other bodies larger than maxSize are rejected with ErrResponseTooLarge
+func processResponse(res *http.Response, decodeTo interface{}, maxSize int64) error {
+	defer res.Body.Close()
+	body := io.Reader(res.Body)
+	if res.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(res.Body)
+		if err != nil {
+			return fmt.Errorf("unable to decompress response body: %w", err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+	ok := res.StatusCode == http.StatusOK || res.StatusCode == http.StatusCreated
+	if w, isWriter := decodeTo.(io.Writer); ok && isWriter {
+		// streamed responses are copied as they arrive, without reading the whole body into memory
+		if _, err := io.Copy(w, body); err != nil {
+			return fmt.Errorf("unable to read response body: %w", err)
+		}
+		return nil
+	}
+	resBody, err := ioutil.ReadAll(io.LimitReader(body, maxSize+1))
 	if err != nil {
 		return fmt.Errorf("unable to read response body: %w", err)
 	}
-	defer res.Body.Close()
+	if int64(len(resBody)) > maxSize {
+		return fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, maxSize)
+	}
 
-	if res.StatusCode == http.StatusOK || res.StatusCode == http.StatusCreated {
+	if ok {
 		if decodeTo != nil {
 			return json.Unmarshal(resBody, decodeTo)
 		} else {
@@ -53,6 +124,25 @@ func processResponse(res *http.Response, decodeTo interface{}) error {
 }
 
 func (c *WaspClient) do(method string, route string, reqObj interface{}, resObj interface{}) error {
+	return c.doCtx(context.Background(), method, route, reqObj, resObj)
+}
+
+// doCtx makes the request with the given context. Each request carries a correlation ID in the header,
+// which is also included in the returned error
+func (c *WaspClient) doCtx(ctx context.Context, method string, route string, reqObj interface{}, resObj interface{}) error {
+	reqID := requestID(ctx)
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.wait(ctx); err != nil {
+			return fmt.Errorf("[request %s] %w", reqID, err)
+		}
+	}
+	if err := c.doRequest(ctx, reqID, method, route, reqObj, resObj); err != nil {
+		return fmt.Errorf("[request %s] %w", reqID, err)
+	}
+	return nil
+}
+
+func (c *WaspClient) doRequest(ctx context.Context, reqID string, method string, route string, reqObj interface{}, resObj interface{}) error {
 	// marshal request object
 	var data []byte
 	if reqObj != nil {
@@ -65,7 +155,7 @@ func (c *WaspClient) do(method string, route string, reqObj interface{}, resObj
 
 	// construct request
 	url := fmt.Sprintf("%s/%s", strings.TrimRight(c.baseURL, "/"), strings.TrimLeft(route, "/"))
-	req, err := http.NewRequest(method, url, func() io.Reader {
+	req, err := http.NewRequestWithContext(ctx, method, url, func() io.Reader {
 		if data == nil {
 			return nil
 		}
@@ -75,6 +165,17 @@ func (c *WaspClient) do(method string, route string, reqObj interface{}, resObj
 		return err
 	}
 
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set(c.getRequestIDHeader(), reqID)
+	// the response is decompressed in processResponse, since setting the header explicitly
+	// disables the transparent decompression of http.Transport
+	if c.disableCompression {
+		req.Header.Set("Accept-Encoding", "identity")
+	} else {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
 	if data != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
@@ -82,11 +183,23 @@ func (c *WaspClient) do(method string, route string, reqObj interface{}, resObj
 	// make the request
 	res, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("Request failed: %v", err)
+		return fmt.Errorf("Request failed: %w", err)
 	}
 
 	// write response into response object
-	return processResponse(res, resObj)
+	maxSize := c.maxResponseSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxResponseSize
+	}
+	return processResponse(res, resObj, maxSize)
+}
+
+// Close releases the idle connections to the node. The client is still usable afterwards.
+// The connections of an http.Client given to NewWaspClient are left to its owner
+func (c *WaspClient) Close() {
+	if c.transport != nil {
+		c.transport.CloseIdleConnections()
+	}
 }
 
 // BaseURL returns the baseURL of the client.
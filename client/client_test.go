@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/model/statequery"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, handler echo.HandlerFunc) *httptest.Server {
+	e := echo.New()
+	e.GET(routes.Info(), handler)
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHeaders(t *testing.T) {
+	srv := newTestServer(t, func(c echo.Context) error {
+		if c.Request().Header.Get("Authorization") != "Bearer secret" {
+			return echo.ErrUnauthorized
+		}
+		return c.JSON(http.StatusOK, model.InfoResponse{Version: c.Request().Header.Get("X-Custom")})
+	})
+
+	_, err := NewWaspClient(srv.URL).Info()
+	require.Error(t, err)
+
+	info, err := NewWaspClient(srv.URL).WithBearerToken("secret").WithHeader("X-Custom", "custom").Info()
+	require.NoError(t, err)
+	require.EqualValues(t, "custom", info.Version)
+}
+
+func TestRequestID(t *testing.T) {
+	srv := newTestServer(t, func(c echo.Context) error {
+		return c.JSON(http.StatusNotFound, model.NewHTTPError(http.StatusNotFound, c.Request().Header.Get("X-Correlation")))
+	})
+
+	_, err := NewWaspClient(srv.URL).Info()
+	require.True(t, model.IsHTTPNotFound(err))
+
+	c := NewWaspClient(srv.URL).WithRequestIDHeader("X-Correlation")
+	err = c.doCtx(ContextWithRequestID(context.Background(), "req123"), http.MethodGet, routes.Info(), nil, nil)
+	require.True(t, model.IsHTTPNotFound(err))
+	require.Contains(t, err.Error(), "[request req123]")
+	var httpErr *model.HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	require.EqualValues(t, "req123", httpErr.Message)
+}
+
+func TestCompression(t *testing.T) {
+	e := echo.New()
+	e.Use(middleware.Gzip())
+	e.GET(routes.Info(), func(c echo.Context) error {
+		return c.JSON(http.StatusOK, model.InfoResponse{Version: c.Request().Header.Get("Accept-Encoding")})
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	info, err := NewWaspClient(srv.URL).Info()
+	require.NoError(t, err)
+	require.EqualValues(t, "gzip", info.Version)
+
+	info, err = NewWaspClient(srv.URL).WithoutCompression().Info()
+	require.NoError(t, err)
+	require.EqualValues(t, "identity", info.Version)
+}
+
+func TestRateLimit(t *testing.T) {
+	srv := newTestServer(t, func(c echo.Context) error {
+		return c.JSON(http.StatusOK, model.InfoResponse{})
+	})
+	c := NewWaspClient(srv.URL).WithRateLimit(10, 2)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := c.Info()
+		require.NoError(t, err)
+	}
+	// the burst is sent right away, the other two wait for 100ms each
+	require.True(t, time.Since(start) >= 150*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := c.doCtx(ctx, http.MethodGet, routes.Info(), nil, nil)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestSlowServerContext(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	release := make(chan struct{})
+	e := echo.New()
+	e.GET(routes.StateQuery(chainID.String()), func(c echo.Context) error {
+		<-release
+		return c.NoContent(http.StatusOK)
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	// before closing the server, which waits for the handlers
+	t.Cleanup(func() { close(release) })
+	c := NewWaspClient(srv.URL)
+
+	// the HTTP call is the one which is interrupted
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.StateQueryCtx(ctx, &chainID, statequery.NewRequest())
+	require.True(t, errors.Is(err, context.DeadlineExceeded), err)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = c.StateQueryCtx(ctx, &chainID, statequery.NewRequest())
+	require.True(t, errors.Is(err, context.Canceled), err)
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	srv := newTestServer(t, func(c echo.Context) error {
+		return c.JSON(http.StatusOK, model.InfoResponse{Version: strings.Repeat("v", 10000)})
+	})
+
+	_, err := NewWaspClient(srv.URL).WithMaxResponseSize(1000).Info()
+	require.True(t, errors.Is(err, ErrResponseTooLarge))
+
+	_, err = NewWaspClient(srv.URL).WithMaxResponseSize(1000).WithoutCompression().Info()
+	require.True(t, errors.Is(err, ErrResponseTooLarge))
+
+	info, err := NewWaspClient(srv.URL).WithMaxResponseSize(20000).Info()
+	require.NoError(t, err)
+	require.Len(t, info.Version, 10000)
+
+	_, err = NewWaspClient(srv.URL).Info()
+	require.NoError(t, err)
+}
+
+func TestCloseOwnTransport(t *testing.T) {
+	c := NewWaspClient("localhost:9090")
+	require.NotNil(t, c.transport)
+	require.True(t, c.httpClient.Transport != http.DefaultTransport)
+	c.Close()
+
+	// the transport of a given client is not closed
+	given := NewWaspClient("localhost:9090", http.Client{})
+	require.Nil(t, given.transport)
+	given.Close()
+}
@@ -0,0 +1,18 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+)
+
+// GetChainCommitteeInfo fetches the committee nodes, the quorum and the shared public key of the chain
+func (c *WaspClient) GetChainCommitteeInfo(chainID coretypes.ChainID) (*model.CommitteeInfo, error) {
+	res := &model.CommitteeInfo{}
+	if err := c.do(http.MethodGet, routes.GetChainCommitteeInfo(chainID.String()), nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
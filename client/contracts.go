@@ -0,0 +1,52 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// ErrChainNotFound is returned when the node doesn't run the requested chain
+var ErrChainNotFound = errors.New("chain not found")
+
+// ContractInfo describes a smart contract deployed on a chain
+type ContractInfo struct {
+	Hname       coretypes.Hname
+	Name        string
+	Description string
+	ProgramHash hashing.HashValue
+}
+
+// GetContractList fetches the contracts deployed on the chain from its contract registry, sorted by hname
+func (c *WaspClient) GetContractList(chainID coretypes.ChainID) ([]*ContractInfo, error) {
+	info, err := c.CallView(coretypes.NewContractID(chainID, root.Interface.Hname()), root.FuncGetChainInfo, nil)
+	if model.IsHTTPNotFound(err) {
+		return nil, fmt.Errorf("%w: %s", ErrChainNotFound, chainID.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+	contracts, err := root.DecodeContractRegistry(collections.NewMapReadOnly(info, root.VarContractRegistry))
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*ContractInfo, 0, len(contracts))
+	for hname, rec := range contracts {
+		ret = append(ret, &ContractInfo{
+			Hname:       hname,
+			Name:        rec.Name,
+			Description: rec.Description,
+			ProgramHash: rec.ProgramHash,
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Hname < ret[j].Hname
+	})
+	return ret, nil
+}
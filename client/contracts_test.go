@@ -0,0 +1,53 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetContractList(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	rec := &root.ContractRecord{
+		ProgramHash: hashing.HashStrings("program"),
+		Description: "test contract",
+		Name:        "test",
+	}
+
+	e := echo.New()
+	rootContractID := coretypes.NewContractID(chainID, root.Interface.Hname())
+	e.GET(routes.CallView(rootContractID.Base58(), root.FuncGetChainInfo), func(c echo.Context) error {
+		ret := dict.New()
+		collections.NewMap(ret, root.VarContractRegistry).MustSetAt(rec.Hname().Bytes(), root.EncodeContractRecord(rec))
+		return c.JSON(http.StatusOK, ret)
+	})
+	e.GET(routes.CallView(":contractID", ":fname"), func(c echo.Context) error {
+		return c.JSON(http.StatusNotFound, model.NewHTTPError(http.StatusNotFound, "Chain not found"))
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	contracts, err := NewWaspClient(srv.URL).GetContractList(chainID)
+	require.NoError(t, err)
+	require.Len(t, contracts, 1)
+	require.EqualValues(t, &ContractInfo{
+		Hname:       coretypes.Hn("test"),
+		Name:        "test",
+		Description: "test contract",
+		ProgramHash: rec.ProgramHash,
+	}, contracts[0])
+
+	_, err = NewWaspClient(srv.URL).GetContractList(coretypes.NewRandomChainID())
+	require.True(t, errors.Is(err, ErrChainNotFound))
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/webapi/model"
@@ -15,3 +16,46 @@ func (c *WaspClient) DumpSCState(scid *coretypes.ContractID) (*model.SCStateDump
 	}
 	return res, nil
 }
+
+// NodeDump is a serializable diagnostic snapshot of the node, suitable to be attached to bug reports.
+// It contains public information only: no private keys or key shares are included
+type NodeDump struct {
+	BaseURL      string               `json:"baseURL"`
+	Info         *model.InfoResponse  `json:"info"`
+	ChainRecords []*model.ChainRecord `json:"chainRecords"`
+	ActiveChains []model.ChainID      `json:"activeChains"`
+	CommitteeOf  []model.ChainID      `json:"committeeOf"` // chains with the node in the committee
+	CreatedAt    time.Time            `json:"createdAt"`
+}
+
+// DumpState collects the node info and all chain records of the node into a NodeDump
+func (c *WaspClient) DumpState() (*NodeDump, error) {
+	info, err := c.Info()
+	if err != nil {
+		return nil, err
+	}
+	var records []*model.ChainRecord
+	if err := c.do(http.MethodGet, routes.ListChainRecords(), nil, &records); err != nil {
+		return nil, err
+	}
+	ret := &NodeDump{
+		BaseURL:      c.baseURL,
+		Info:         info,
+		ChainRecords: records,
+		ActiveChains: make([]model.ChainID, 0),
+		CommitteeOf:  make([]model.ChainID, 0),
+		CreatedAt:    time.Now().UTC(),
+	}
+	for _, rec := range records {
+		if rec.Active {
+			ret.ActiveChains = append(ret.ActiveChains, rec.ChainID)
+		}
+		for _, node := range rec.CommitteeNodes {
+			if node == info.NetworkId {
+				ret.CommitteeOf = append(ret.CommitteeOf, rec.ChainID)
+				break
+			}
+		}
+	}
+	return ret, nil
+}
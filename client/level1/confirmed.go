@@ -0,0 +1,26 @@
+package level1
+
+import (
+	"errors"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+)
+
+// ConfirmationClient is implemented by Level1Client implementations which can check the confirmation
+// of a transaction without blocking
+type ConfirmationClient interface {
+	// IsConfirmed checks if the transaction is confirmed in the ledger
+	IsConfirmed(txid transaction.ID) (bool, error)
+}
+
+// ErrConfirmationNotSupported is returned by IsConfirmed if the client doesn't implement ConfirmationClient
+var ErrConfirmationNotSupported = errors.New("level1 client can't check the confirmation of a transaction")
+
+// IsConfirmed checks if the transaction is confirmed in the ledger, e.g. to poll a transaction
+// posted with PostTransaction on the own schedule of the caller
+func IsConfirmed(client Level1Client, txid transaction.ID) (bool, error) {
+	if cc, ok := client.(ConfirmationClient); ok {
+		return cc.IsConfirmed(txid)
+	}
+	return false, ErrConfirmationNotSupported
+}
@@ -53,16 +53,45 @@ func (api *goshimmerClient) balanceIOTA(targetAddress *address.Address) (int64,
 	return bals[balance.ColorIOTA], nil
 }
 
-func (api *goshimmerClient) GetConfirmedAccountOutputs(address *address.Address) (map[valuetransaction.OutputID][]*balance.Balance, error) {
-	r, err := api.goshimmerClient.GetUnspentOutputs([]string{address.String()})
+func (api *goshimmerClient) GetConfirmedAccountOutputs(addr *address.Address) (map[valuetransaction.OutputID][]*balance.Balance, error) {
+	outs, err := api.GetConfirmedAccountOutputsMulti([]*address.Address{addr})
+	if err != nil {
+		return nil, err
+	}
+	ret, ok := outs[*addr]
+	if !ok {
+		ret = make(map[valuetransaction.OutputID][]*balance.Balance)
+	}
+	return ret, nil
+}
+
+// GetConfirmedAccountOutputsMulti fetches confirmed outputs of all addresses in one GetUnspentOutputs call
+func (api *goshimmerClient) GetConfirmedAccountOutputsMulti(addrs []*address.Address) (map[address.Address]map[valuetransaction.OutputID][]*balance.Balance, error) {
+	addrStrings := make([]string, len(addrs))
+	for i, addr := range addrs {
+		addrStrings[i] = addr.String()
+	}
+	r, err := api.goshimmerClient.GetUnspentOutputs(addrStrings)
 	if err != nil {
 		return nil, fmt.Errorf("GetUnspentOutputs: %s", err)
 	}
 	if r.Error != "" {
 		return nil, fmt.Errorf("%s", r.Error)
 	}
-	ret := make(map[valuetransaction.OutputID][]*balance.Balance)
+	ret := make(map[address.Address]map[valuetransaction.OutputID][]*balance.Balance)
+	for _, addr := range addrs {
+		ret[*addr] = make(map[valuetransaction.OutputID][]*balance.Balance)
+	}
 	for _, out := range r.UnspentOutputs {
+		addr, err := address.FromBase58(out.Address)
+		if err != nil {
+			return nil, fmt.Errorf("FromBase58: %s", err)
+		}
+		addrOuts, ok := ret[addr]
+		if !ok {
+			addrOuts = make(map[valuetransaction.OutputID][]*balance.Balance)
+			ret[addr] = addrOuts
+		}
 		for _, outid := range out.OutputIDs {
 			if !outid.InclusionState.Confirmed {
 				continue
@@ -79,7 +108,7 @@ func (api *goshimmerClient) GetConfirmedAccountOutputs(address *address.Address)
 				}
 				balances = append(balances, &balance.Balance{Value: b.Value, Color: color})
 			}
-			ret[id] = balances
+			addrOuts[id] = balances
 		}
 	}
 	return ret, nil
@@ -109,13 +138,22 @@ func (api *goshimmerClient) PostAndWaitForConfirmation(tx *valuetransaction.Tran
 func (api *goshimmerClient) WaitForConfirmation(txid valuetransaction.ID) error {
 	for {
 		time.Sleep(1 * time.Second)
-		tx, err := api.goshimmerClient.GetTransactionByID(txid.String())
+		confirmed, err := api.IsConfirmed(txid)
 		if err != nil {
 			return err
 		}
-		if tx.InclusionState.Confirmed {
+		if confirmed {
 			break
 		}
 	}
 	return nil
 }
+
+// IsConfirmed implements level1.ConfirmationClient
+func (api *goshimmerClient) IsConfirmed(txid valuetransaction.ID) (bool, error) {
+	tx, err := api.goshimmerClient.GetTransactionByID(txid.String())
+	if err != nil {
+		return false, err
+	}
+	return tx.InclusionState.Confirmed, nil
+}
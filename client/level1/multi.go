@@ -0,0 +1,35 @@
+package level1
+
+import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+)
+
+// MultiAccountOutputsClient is implemented by Level1Client implementations which can fetch outputs
+// of several addresses in one call
+type MultiAccountOutputsClient interface {
+	// GetConfirmedAccountOutputsMulti fetches all confirmed outputs belonging to each of the given addresses
+	GetConfirmedAccountOutputsMulti(addrs []*address.Address) (map[address.Address]map[transaction.OutputID][]*balance.Balance, error)
+}
+
+// GetConfirmedAccountOutputsMulti fetches confirmed outputs of all given addresses.
+// It uses one batch call if the client implements MultiAccountOutputsClient and falls back to
+// calling GetConfirmedAccountOutputs for each address otherwise
+func GetConfirmedAccountOutputsMulti(client Level1Client, addrs []*address.Address) (map[address.Address]map[transaction.OutputID][]*balance.Balance, error) {
+	if mc, ok := client.(MultiAccountOutputsClient); ok {
+		return mc.GetConfirmedAccountOutputsMulti(addrs)
+	}
+	ret := make(map[address.Address]map[transaction.OutputID][]*balance.Balance)
+	for _, addr := range addrs {
+		if _, ok := ret[*addr]; ok {
+			continue
+		}
+		outs, err := client.GetConfirmedAccountOutputs(addr)
+		if err != nil {
+			return nil, err
+		}
+		ret[*addr] = outs
+	}
+	return ret, nil
+}
@@ -0,0 +1,60 @@
+package level1
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/stretchr/testify/require"
+)
+
+type singleAddressClient struct {
+	Level1Client
+	calls int
+}
+
+func (c *singleAddressClient) GetConfirmedAccountOutputs(addr *address.Address) (map[transaction.OutputID][]*balance.Balance, error) {
+	c.calls++
+	id := transaction.NewOutputID(*addr, transaction.ID{})
+	return map[transaction.OutputID][]*balance.Balance{
+		id: {balance.New(balance.ColorIOTA, 1)},
+	}, nil
+}
+
+func TestGetConfirmedAccountOutputsMultiFallback(t *testing.T) {
+	addr1 := address.Random()
+	addr2 := address.Random()
+	client := &singleAddressClient{}
+
+	outs, err := GetConfirmedAccountOutputsMulti(client, []*address.Address{&addr1, &addr2, &addr1})
+	require.NoError(t, err)
+	require.Equal(t, 2, client.calls)
+	require.Len(t, outs, 2)
+	require.Len(t, outs[addr1], 1)
+	require.Len(t, outs[addr2], 1)
+}
+
+type confirmationClient struct {
+	Level1Client
+	confirmed map[transaction.ID]bool
+}
+
+func (c *confirmationClient) IsConfirmed(txid transaction.ID) (bool, error) {
+	return c.confirmed[txid], nil
+}
+
+func TestIsConfirmed(t *testing.T) {
+	txid := transaction.ID{1}
+	client := &confirmationClient{confirmed: map[transaction.ID]bool{txid: true}}
+
+	ok, err := IsConfirmed(client, txid)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = IsConfirmed(client, transaction.ID{2})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = IsConfirmed(&singleAddressClient{}, txid)
+	require.Equal(t, ErrConfirmationNotSupported, err)
+}
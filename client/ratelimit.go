@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket: the bucket holds up to burst tokens and is refilled with rps tokens per second.
+// Each request takes one token, waiting for it if the bucket is empty
+type rateLimiter struct {
+	mutex  sync.Mutex
+	rps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rps int, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rps:    float64(rps),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait takes a token from the bucket, blocking until it is available or the context is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mutex.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rps
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	// the token is reserved right away, so concurrent requests queue up behind each other
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rps * float64(time.Second))
+	l.mutex.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// give the reserved token back
+		l.mutex.Lock()
+		l.tokens++
+		l.mutex.Unlock()
+		return ctx.Err()
+	}
+}
+
+// WithRateLimit limits the requests sent by the client to rps per second on average, allowing bursts
+// of up to burst requests. Requests exceeding the limit wait for their turn or until their context is done.
+// rps <= 0 disables the limit
+func (c *WaspClient) WithRateLimit(rps int, burst int) *WaspClient {
+	if rps <= 0 {
+		c.rateLimiter = nil
+		return c
+	}
+	c.rateLimiter = newRateLimiter(rps, burst)
+	return c
+}
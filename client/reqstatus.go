@@ -19,6 +19,21 @@ func (c *WaspClient) RequestStatus(chainId *coretypes.ChainID, reqId *coretypes.
 	return res, nil
 }
 
+// GetPendingRequests fetches the requests to the contract which are received by the committee
+// and not processed yet, in the order they were received. It is empty if there are none.
+// A node outside the committee doesn't know them and responds with 409 Conflict (see model.IsHTTPConflict)
+func (c *WaspClient) GetPendingRequests(chainID coretypes.ChainID, scHname coretypes.Hname) ([]model.RequestInfo, error) {
+	res := make([]model.RequestInfo, 0)
+	contractID := coretypes.NewContractID(chainID, scHname)
+	if err := c.do(http.MethodGet, routes.PendingRequests(contractID.Base58()), nil, &res); err != nil {
+		return nil, err
+	}
+	if res == nil {
+		res = make([]model.RequestInfo, 0)
+	}
+	return res, nil
+}
+
 // WaitUntilRequestProcessed blocks until the request has been processed by the node
 func (c *WaspClient) WaitUntilRequestProcessed(chainId *coretypes.ChainID, reqId *coretypes.RequestID, timeout time.Duration) error {
 	if timeout == 0 {
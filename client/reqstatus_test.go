@@ -0,0 +1,57 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPendingRequests(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	hname := coretypes.Hn("tokenregistry")
+	reqID := coretypes.NewRequestID(transaction.ID{1}, 0)
+	pending := []model.RequestInfo{{
+		RequestID:    reqID.Base58(),
+		EntryPoint:   coretypes.Hn("mintSupply").String(),
+		WhenReceived: time.Unix(1600000000, 0).UTC(),
+	}}
+	idleHname := coretypes.Hn("idle")
+	e := echo.New()
+	e.GET(routes.PendingRequests(coretypes.NewContractID(chainID, hname).Base58()), func(c echo.Context) error {
+		return c.JSON(http.StatusOK, pending)
+	})
+	e.GET(routes.PendingRequests(coretypes.NewContractID(chainID, idleHname).Base58()), func(c echo.Context) error {
+		return c.JSON(http.StatusOK, []model.RequestInfo{})
+	})
+	outsiderChainID := coretypes.NewRandomChainID()
+	e.GET(routes.PendingRequests(coretypes.NewContractID(outsiderChainID, hname).Base58()), func(c echo.Context) error {
+		return c.JSON(http.StatusConflict, model.NewHTTPError(http.StatusConflict, "not in the committee"))
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	c := NewWaspClient(srv.URL)
+	res, err := c.GetPendingRequests(chainID, hname)
+	require.NoError(t, err)
+	require.EqualValues(t, pending, res)
+
+	res, err = c.GetPendingRequests(chainID, idleHname)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	require.Empty(t, res)
+
+	_, err = c.GetPendingRequests(coretypes.NewRandomChainID(), hname)
+	require.True(t, model.IsHTTPNotFound(err))
+
+	// a node outside the committee doesn't know the backlog
+	_, err = c.GetPendingRequests(outsiderChainID, hname)
+	require.True(t, model.IsHTTPConflict(err))
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+)
+
+// ErrTracingDisabled is returned by GetRequestTrace when the node doesn't serve request traces
+var ErrTracingDisabled = errors.New("request tracing is not enabled on the node")
+
+// GetRequestTrace fetches the trace of the processing of the request by the VM, e.g. to find out why a request failed.
+// Nodes with request tracing disabled respond with 501 Not Implemented and ErrTracingDisabled is returned.
+// The trace is read from the block of the request, so it is not found if the block was pruned
+func (c *WaspClient) GetRequestTrace(chainID coretypes.ChainID, reqID coretypes.RequestID) (*model.RequestTrace, error) {
+	res := &model.RequestTrace{}
+	err := c.do(http.MethodGet, routes.RequestTrace(chainID.String(), reqID.Base58()), nil, res)
+	var httpErr *model.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotImplemented {
+		return nil, ErrTracingDisabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRequestTrace(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	reqID := coretypes.NewRequestID(transaction.ID{1}, 0)
+	untracedReqID := coretypes.NewRequestID(transaction.ID{2}, 0)
+	trace := &model.RequestTrace{
+		RequestID:  reqID.Base58(),
+		Contract:   coretypes.Hn("tokenregistry").String(),
+		EntryPoint: coretypes.Hn("mintSupply").String(),
+		BlockIndex: 3,
+		Mutations:  []model.StateMutation{{Key: model.NewBytes([]byte("k")), Value: model.NewBytes([]byte("v"))}},
+		Error:      "supply must be positive",
+	}
+	e := echo.New()
+	e.GET(routes.RequestTrace(chainID.String(), reqID.Base58()), func(c echo.Context) error {
+		return c.JSON(http.StatusOK, trace)
+	})
+	e.GET(routes.RequestTrace(chainID.String(), untracedReqID.Base58()), func(c echo.Context) error {
+		return c.JSON(http.StatusNotFound, model.NewHTTPError(http.StatusNotFound, "request not traced"))
+	})
+	disabledChainID := coretypes.NewRandomChainID()
+	e.GET(routes.RequestTrace(disabledChainID.String(), reqID.Base58()), func(c echo.Context) error {
+		return c.JSON(http.StatusNotImplemented, model.NewHTTPError(http.StatusNotImplemented, "tracing disabled"))
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	c := NewWaspClient(srv.URL)
+	res, err := c.GetRequestTrace(chainID, reqID)
+	require.NoError(t, err)
+	require.EqualValues(t, trace, res)
+
+	_, err = c.GetRequestTrace(chainID, untracedReqID)
+	require.True(t, model.IsHTTPNotFound(err))
+	require.False(t, errors.Is(err, ErrTracingDisabled))
+
+	_, err = c.GetRequestTrace(disabledChainID, reqID)
+	require.Equal(t, ErrTracingDisabled, err)
+
+	// a node without the route is not mistaken for a node with tracing disabled
+	_, err = c.GetRequestTrace(coretypes.NewRandomChainID(), reqID)
+	require.True(t, model.IsHTTPNotFound(err))
+	require.False(t, errors.Is(err, ErrTracingDisabled))
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// DefaultRequestIDHeader is the header carrying the correlation ID of each request
+const DefaultRequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a context which makes WaspClient send the given correlation ID
+// instead of a randomly generated one
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// WithRequestIDHeader sets the name of the header carrying the correlation ID of each request
+func (c *WaspClient) WithRequestIDHeader(name string) *WaspClient {
+	c.requestIDHeader = name
+	return c
+}
+
+func (c *WaspClient) getRequestIDHeader() string {
+	if c.requestIDHeader == "" {
+		return DefaultRequestIDHeader
+	}
+	return c.requestIDHeader
+}
+
+// requestID returns the correlation ID from the context or a new random one
+func requestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok && id != "" {
+		return id
+	}
+	var b [6]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
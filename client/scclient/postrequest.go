@@ -1,6 +1,8 @@
 package scclient
 
 import (
+	"context"
+
 	"github.com/iotaledger/wasp/client/chainclient"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/sctransaction"
@@ -9,3 +11,26 @@ import (
 func (c *SCClient) PostRequest(fname string, params ...chainclient.PostRequestParams) (*sctransaction.Transaction, error) {
 	return c.ChainClient.PostRequest(c.ContractHname, coretypes.Hn(fname), params...)
 }
+
+// BuildRequest builds and signs the request to the entry point of the contract without posting it.
+// req.ContractHname and req.EntryPoint are set by the client, see chainclient.Client.BuildRequest
+func (c *SCClient) BuildRequest(entryPoint coretypes.Hname, req chainclient.RequestParams) (*sctransaction.Transaction, error) {
+	return c.ChainClient.BuildRequest(c.requestParams(entryPoint, req))
+}
+
+// BuildRequestUnsigned is like BuildRequest, but leaves the transaction unsigned
+func (c *SCClient) BuildRequestUnsigned(entryPoint coretypes.Hname, req chainclient.RequestParams) (*sctransaction.Transaction, error) {
+	return c.ChainClient.BuildRequestUnsigned(c.requestParams(entryPoint, req))
+}
+
+// PostRequestAndWait builds the request to the entry point of the contract, posts it and waits
+// until it is processed, see chainclient.Client.PostRequestAndWait
+func (c *SCClient) PostRequestAndWait(ctx context.Context, entryPoint coretypes.Hname, req chainclient.RequestParams, par chainclient.ConfirmParams) (*sctransaction.Transaction, error) {
+	return c.ChainClient.PostRequestAndWait(ctx, c.requestParams(entryPoint, req), par)
+}
+
+func (c *SCClient) requestParams(entryPoint coretypes.Hname, req chainclient.RequestParams) chainclient.RequestParams {
+	req.ContractHname = c.ContractHname
+	req.EntryPoint = entryPoint
+	return req
+}
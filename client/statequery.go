@@ -1,19 +1,45 @@
-// +build ignore
-
 package client
 
 import (
+	"context"
 	"net/http"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/webapi/model/statequery"
 	"github.com/iotaledger/wasp/packages/webapi/routes"
 )
 
 // StateQuery queries the chain state, and returns the result of the query.
+// Nodes with restricted state readers (webapi.stateReaders) refuse unsigned queries with 403: use StateQuerySigned
 func (c *WaspClient) StateQuery(chainID *coretypes.ChainID, query *statequery.Request) (*statequery.Results, error) {
+	return c.StateQueryCtx(context.Background(), chainID, query)
+}
+
+// StateQueryCtx is like StateQuery, but the HTTP request is canceled when the context is done
+func (c *WaspClient) StateQueryCtx(ctx context.Context, chainID *coretypes.ChainID, query *statequery.Request) (*statequery.Results, error) {
+	res := &statequery.Results{}
+	if err := c.doCtx(ctx, http.MethodGet, routes.StateQuery(chainID.String()), query, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// StateQuerySigned is like StateQuery, but the query is signed with the given signature scheme,
+// so that the node can authorize the signer. Only the agents listed in the webapi.stateReaders
+// of the node are allowed to read
+func (c *WaspClient) StateQuerySigned(chainID *coretypes.ChainID, query *statequery.Request, sigScheme signaturescheme.SignatureScheme) (*statequery.Results, error) {
+	return c.StateQuerySignedCtx(context.Background(), chainID, query, sigScheme)
+}
+
+// StateQuerySignedCtx is like StateQuerySigned, but the HTTP request is canceled when the context is done
+func (c *WaspClient) StateQuerySignedCtx(ctx context.Context, chainID *coretypes.ChainID, query *statequery.Request, sigScheme signaturescheme.SignatureScheme) (*statequery.Results, error) {
+	signed, err := query.Sign(chainID, sigScheme)
+	if err != nil {
+		return nil, err
+	}
 	res := &statequery.Results{}
-	if err := c.do(http.MethodGet, routes.StateQuery(chainID.String()), query, res); err != nil {
+	if err := c.doCtx(ctx, http.MethodPost, routes.StateQuerySigned(chainID.String()), signed, res); err != nil {
 		return nil, err
 	}
 	return res, nil
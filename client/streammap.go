@@ -0,0 +1,111 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+)
+
+// ErrIncompleteMapStream is returned when the stream of the entries of a map ends without the model.MapStreamEnd
+// line, or the number of the entries doesn't match it, e.g. because the node failed in the middle of the stream
+var ErrIncompleteMapStream = errors.New("incomplete map stream")
+
+// StreamStateMap writes all entries of the map in the state of the contract to w as newline-delimited JSON,
+// one model.MapEntry per line, while they are streamed by the node. The map is never held in memory,
+// so maps of any size can be exported, e.g. to back up a large registry. Use ReadMapStream to parse it.
+// The stream ends with the model.MapStreamEnd line. If it is missing, ErrIncompleteMapStream is returned
+// after all the received entries are written to w.
+// Nodes with restricted state readers (webapi.stateReaders) refuse the stream with 403
+func (c *WaspClient) StreamStateMap(contractID coretypes.ContractID, mapName string, w io.Writer) error {
+	checker := &mapStreamChecker{w: w}
+	if err := c.do(http.MethodGet, routes.StreamStateMap(contractID.Base58(), url.PathEscape(mapName)), nil, checker); err != nil {
+		return err
+	}
+	return checker.check()
+}
+
+// ReadMapStream parses the stream written by StreamStateMap and calls f for each entry, in order.
+// It returns ErrIncompleteMapStream if the stream doesn't end with a matching model.MapStreamEnd line
+func ReadMapStream(r io.Reader, f func(key []byte, value []byte) error) error {
+	dec := json.NewDecoder(r)
+	count := uint32(0)
+	for {
+		var line mapStreamLine
+		if err := dec.Decode(&line); err != nil {
+			if err == io.EOF {
+				return fmt.Errorf("%w: no end of the stream after %d entries", ErrIncompleteMapStream, count)
+			}
+			return err
+		}
+		if line.Entries != nil {
+			if *line.Entries != count {
+				return fmt.Errorf("%w: expected %d entries, got %d", ErrIncompleteMapStream, *line.Entries, count)
+			}
+			if dec.More() {
+				return fmt.Errorf("unexpected data after the end of the map stream")
+			}
+			return nil
+		}
+		if err := f(line.Key.Bytes(), line.Value.Bytes()); err != nil {
+			return err
+		}
+		count++
+	}
+}
+
+// mapStreamLine is either a model.MapEntry or, if Entries is not nil, the model.MapStreamEnd line
+type mapStreamLine struct {
+	model.MapEntry
+	Entries *uint32 `json:"entries"`
+}
+
+// mapStreamChecker passes the stream to w while counting the lines and keeping the last one,
+// to check the end of the stream without parsing the entries
+type mapStreamChecker struct {
+	w        io.Writer
+	lines    uint32
+	lastLine []byte
+	partial  []byte
+}
+
+func (m *mapStreamChecker) Write(p []byte) (int, error) {
+	n, err := m.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	for rest := p; len(rest) > 0; {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			m.partial = append(m.partial, rest...)
+			break
+		}
+		m.lastLine = append(m.partial[:0:0], m.partial...)
+		m.lastLine = append(m.lastLine, rest[:i]...)
+		m.partial = m.partial[:0]
+		m.lines++
+		rest = rest[i+1:]
+	}
+	return n, nil
+}
+
+func (m *mapStreamChecker) check() error {
+	if len(bytes.TrimSpace(m.partial)) > 0 || m.lines == 0 {
+		return fmt.Errorf("%w: the stream is cut", ErrIncompleteMapStream)
+	}
+	var line mapStreamLine
+	if err := json.Unmarshal(m.lastLine, &line); err != nil || line.Entries == nil {
+		return fmt.Errorf("%w: no end of the stream after %d lines", ErrIncompleteMapStream, m.lines)
+	}
+	if *line.Entries != m.lines-1 {
+		return fmt.Errorf("%w: expected %d entries, got %d", ErrIncompleteMapStream, *line.Entries, m.lines-1)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamStateMap(t *testing.T) {
+	contractID := coretypes.NewContractID(coretypes.NewRandomChainID(), coretypes.Hn("tokenregistry"))
+	const n = 1000
+
+	e := echo.New()
+	e.GET(routes.StreamStateMap(contractID.Base58(), ":mapName"), func(c echo.Context) error {
+		if c.Param("mapName") == "other" {
+			return echo.NewHTTPError(http.StatusNotFound)
+		}
+		res := c.Response()
+		res.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+		res.WriteHeader(http.StatusOK)
+		enc := json.NewEncoder(res)
+		for i := 0; i < n; i++ {
+			entry := &model.MapEntry{
+				Key:   model.NewBytes([]byte(fmt.Sprintf("key%d", i))),
+				Value: model.NewBytes([]byte(fmt.Sprintf("value%d", i))),
+			}
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+			res.Flush()
+		}
+		if c.Param("mapName") == "cut" {
+			// the node fails in the middle of the stream
+			return nil
+		}
+		return enc.Encode(&model.MapStreamEnd{Entries: n})
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	var buf bytes.Buffer
+	require.NoError(t, NewWaspClient(srv.URL).StreamStateMap(contractID, "tr", &buf))
+	require.EqualValues(t, n+1, bytes.Count(buf.Bytes(), []byte("\n")))
+
+	i := 0
+	err := ReadMapStream(&buf, func(key []byte, value []byte) error {
+		require.EqualValues(t, fmt.Sprintf("key%d", i), string(key))
+		require.EqualValues(t, fmt.Sprintf("value%d", i), string(value))
+		i++
+		return nil
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, n, i)
+
+	err = NewWaspClient(srv.URL).StreamStateMap(contractID, "other", &buf)
+	require.Error(t, err)
+
+	buf.Reset()
+	err = NewWaspClient(srv.URL).StreamStateMap(contractID, "cut", &buf)
+	require.True(t, errors.Is(err, ErrIncompleteMapStream))
+	err = ReadMapStream(&buf, func(key []byte, value []byte) error {
+		return nil
+	})
+	require.True(t, errors.Is(err, ErrIncompleteMapStream))
+}
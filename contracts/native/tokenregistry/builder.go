@@ -0,0 +1,96 @@
+package tokenregistry
+
+import (
+	"fmt"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+const (
+	// MaxDescriptionLength is the maximum length of the description of the supply in bytes
+	MaxDescriptionLength = 150
+	// MaxUserDefinedSize is the maximum size of the user defined data, limited by the 16 bit length prefix
+	MaxUserDefinedSize = util.MaxUint16
+)
+
+// TokenMetadataBuilder constructs TokenMetadata records, validating them before they are written
+type TokenMetadataBuilder struct {
+	tm TokenMetadata
+}
+
+func NewTokenMetadataBuilder() *TokenMetadataBuilder {
+	return &TokenMetadataBuilder{}
+}
+
+func (b *TokenMetadataBuilder) Supply(supply int64) *TokenMetadataBuilder {
+	b.tm.Supply = supply
+	return b
+}
+
+func (b *TokenMetadataBuilder) MintedBy(agentID coretypes.AgentID) *TokenMetadataBuilder {
+	b.tm.MintedBy = agentID
+	return b
+}
+
+func (b *TokenMetadataBuilder) Owner(agentID coretypes.AgentID) *TokenMetadataBuilder {
+	b.tm.Owner = agentID
+	return b
+}
+
+// Timestamp sets both Created and Updated
+func (b *TokenMetadataBuilder) Timestamp(ts int64) *TokenMetadataBuilder {
+	b.tm.Created = ts
+	b.tm.Updated = ts
+	return b
+}
+
+func (b *TokenMetadataBuilder) Updated(ts int64) *TokenMetadataBuilder {
+	b.tm.Updated = ts
+	return b
+}
+
+func (b *TokenMetadataBuilder) Description(description string) *TokenMetadataBuilder {
+	b.tm.Description = description
+	return b
+}
+
+func (b *TokenMetadataBuilder) UserDefined(data []byte) *TokenMetadataBuilder {
+	b.tm.UserDefined = data
+	return b
+}
+
+// Build returns a copy of the validated metadata record
+func (b *TokenMetadataBuilder) Build() (*TokenMetadata, error) {
+	if err := b.tm.Validate(); err != nil {
+		return nil, err
+	}
+	return b.tm.Clone(), nil
+}
+
+// Clone returns a deep copy of the metadata record
+func (tm *TokenMetadata) Clone() *TokenMetadata {
+	ret := *tm
+	if tm.UserDefined != nil {
+		ret.UserDefined = make([]byte, len(tm.UserDefined))
+		copy(ret.UserDefined, tm.UserDefined)
+	}
+	return &ret
+}
+
+// Validate checks the constraints of the metadata record
+func (tm *TokenMetadata) Validate() error {
+	if tm.Supply <= 0 {
+		return fmt.Errorf("TokenMetadata: supply must be > 0")
+	}
+	if len(tm.Description) > MaxDescriptionLength {
+		return fmt.Errorf("TokenMetadata: description is %d bytes long, max is %d", len(tm.Description), MaxDescriptionLength)
+	}
+	if len(tm.UserDefined) > MaxUserDefinedSize {
+		return fmt.Errorf("TokenMetadata: user defined data is %d bytes, max is %d", len(tm.UserDefined), MaxUserDefinedSize)
+	}
+	if tm.Updated < tm.Created {
+		return fmt.Errorf("TokenMetadata: updated before created")
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package tokenregistry
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenMetadataBuilder(t *testing.T) {
+	agentID := coretypes.NewRandomAgentID()
+	tm, err := NewTokenMetadataBuilder().
+		Supply(100).
+		MintedBy(agentID).
+		Owner(agentID).
+		Timestamp(1).
+		Description("my tokens").
+		UserDefined([]byte("data")).
+		Build()
+	require.NoError(t, err)
+
+	data, err := util.Bytes(tm)
+	require.NoError(t, err)
+	var decoded TokenMetadata
+	require.NoError(t, decoded.Read(bytes.NewReader(data)))
+	require.EqualValues(t, *tm, decoded)
+}
+
+func TestTokenMetadataBuilderDescriptionTooLong(t *testing.T) {
+	_, err := NewTokenMetadataBuilder().
+		Supply(100).
+		Description(strings.Repeat("x", MaxDescriptionLength+1)).
+		Build()
+	require.Error(t, err)
+}
+
+func TestTokenMetadataJSON(t *testing.T) {
+	tm, err := NewTokenMetadataBuilder().
+		Supply(100).
+		MintedBy(coretypes.NewRandomAgentID()).
+		Owner(coretypes.NewRandomAgentID()).
+		Timestamp(1).
+		Description("my tokens").
+		UserDefined([]byte("data")).
+		Build()
+	require.NoError(t, err)
+
+	data, err := json.Marshal(tm)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"userDefined":"ZGF0YQ=="`)
+	require.Contains(t, string(data), `"mintedBy":"`+tm.MintedBy.Base58()+`"`)
+
+	var decoded TokenMetadata
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.EqualValues(t, tm, &decoded)
+
+	require.Error(t, json.Unmarshal([]byte(`{"mintedBy":"wrong"}`), &decoded))
+}
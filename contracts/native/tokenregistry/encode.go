@@ -1,8 +1,7 @@
-// +build ignore
-
 package tokenregistry
 
 import (
+	"encoding/json"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/util"
 	"io"
@@ -59,3 +58,52 @@ func (tm *TokenMetadata) Write(w io.Writer) error {
 	}
 	return nil
 }
+
+// tokenMetadataJSON is the JSON representation of TokenMetadata for APIs.
+// The binary form of Read/Write is the canonical encoding in the state of the contract
+type tokenMetadataJSON struct {
+	Supply      int64  `json:"supply"`
+	MintedBy    string `json:"mintedBy"` // base58-encoded AgentID
+	Owner       string `json:"owner"`    // base58-encoded AgentID
+	Created     int64  `json:"created"`
+	Updated     int64  `json:"updated"`
+	Description string `json:"description"`
+	UserDefined []byte `json:"userDefined"` // base64-encoded
+}
+
+func (tm *TokenMetadata) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&tokenMetadataJSON{
+		Supply:      tm.Supply,
+		MintedBy:    tm.MintedBy.Base58(),
+		Owner:       tm.Owner.Base58(),
+		Created:     tm.Created,
+		Updated:     tm.Updated,
+		Description: tm.Description,
+		UserDefined: tm.UserDefined,
+	})
+}
+
+func (tm *TokenMetadata) UnmarshalJSON(b []byte) error {
+	var j tokenMetadataJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	mintedBy, err := coretypes.DecodeAgentID(j.MintedBy, coretypes.EncodingBase58)
+	if err != nil {
+		return err
+	}
+	owner, err := coretypes.DecodeAgentID(j.Owner, coretypes.EncodingBase58)
+	if err != nil {
+		return err
+	}
+	*tm = TokenMetadata{
+		Supply:      j.Supply,
+		MintedBy:    mintedBy,
+		Owner:       owner,
+		Created:     j.Created,
+		Updated:     j.Updated,
+		Description: j.Description,
+		UserDefined: j.UserDefined,
+	}
+	return nil
+}
@@ -1,5 +1,3 @@
-// +build ignore
-
 // smart contract code implements Token Registry. User can mint any number of new colored tokens to own address
 // and in the same transaction can register the whole Supply of new tokens in the TokenRegistry.
 // TokenRegistry contains metadata of the supply minted this way. It can be changed by the owner of the record
@@ -21,12 +19,52 @@ import (
 const ProgramHash = "8h2RGcbsUgKckh9rZ4VUF75NUfxP4bj1FC66oSF9us6p"
 const Description = "TokenRegistry, a PoC smart contract"
 
+// names of the entry points of the contract
+const (
+	FuncMintSupply        = "mintSupply"
+	FuncUpdateMetadata    = "updateMetadata"
+	FuncTransferOwnership = "transferOwnership"
+)
+
 var (
-	RequestMintSupply        = coretypes.Hn("mintSupply")
-	RequestUpdateMetadata    = coretypes.Hn("updateMetadata")
-	RequestTransferOwnership = coretypes.Hn("transferOwnership")
+	RequestMintSupply        = coretypes.Hn(FuncMintSupply)
+	RequestUpdateMetadata    = coretypes.Hn(FuncUpdateMetadata)
+	RequestTransferOwnership = coretypes.Hn(FuncTransferOwnership)
 )
 
+// EntryPoints maps the names of the entry points of the contract to their codes
+var EntryPoints = func() map[string]coretypes.Hname {
+	names := []string{FuncMintSupply, FuncUpdateMetadata, FuncTransferOwnership}
+	ret := make(map[string]coretypes.Hname, len(names))
+	for _, name := range names {
+		ret[name] = coretypes.Hn(name)
+	}
+	return ret
+}()
+
+// EntryPointNames maps the codes of the entry points of the contract to their names. It is the inverse of EntryPoints
+var EntryPointNames = func() map[coretypes.Hname]string {
+	ret := make(map[coretypes.Hname]string, len(EntryPoints))
+	for name, code := range EntryPoints {
+		ret[code] = name
+	}
+	return ret
+}()
+
+// ParseEntryPoint returns the code of the entry point of the contract, given by its name (e.g. "mintSupply")
+// or by the string form of the code (see coretypes.Hname.String)
+func ParseEntryPoint(s string) (coretypes.Hname, error) {
+	if code, ok := EntryPoints[s]; ok {
+		return code, nil
+	}
+	if code, err := coretypes.HnameFromString(s); err == nil {
+		if _, ok := EntryPointNames[code]; ok {
+			return code, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown entry point of TokenRegistry: '%s'", s)
+}
+
 const (
 
 	// state vars
@@ -36,6 +74,7 @@ const (
 	// request vars
 	VarReqDescription         = "dscr"
 	VarReqUserDefinedMetadata = "ud"
+	VarReqDeadline            = "dl" // optional, Unix nanoseconds. The request fails if processed after the deadline
 )
 
 // implement Processor and EntryPoint interfaces
@@ -95,13 +134,20 @@ func (ep tokenRegistryEntryPoint) CallView(ctx coretypes.SandboxView) (dict.Dict
 	panic("implement me")
 }
 
-const maxDescription = 150
-
 // mintSupply implements 'mint supply' request
 func mintSupply(ctx coretypes.Sandbox) error {
 	ctx.Event("TokenRegistry: mintSupply")
 	params := ctx.Params()
 
+	// the request block has no expiry: the deadline is checked against the timestamp of the state transition
+	deadline, ok, err := codec.DecodeInt64(params.MustGet(VarReqDeadline))
+	if err != nil {
+		return fmt.Errorf("TokenRegistry: wrong deadline: %v", err)
+	}
+	if ok && ctx.GetTimestamp() > deadline {
+		return fmt.Errorf("TokenRegistry: request expired at %d", deadline)
+	}
+
 	reqId := ctx.RequestID()
 	colorOfTheSupply := (balance.Color)(*reqId.TransactionID())
 
@@ -127,7 +173,7 @@ func mintSupply(ctx coretypes.Sandbox) error {
 	if !ok {
 		description = "no dscr"
 	}
-	description = util.GentleTruncate(description, maxDescription)
+	description = util.GentleTruncate(description, MaxDescriptionLength)
 
 	// get the additional arbitrary deta attached to the supply record
 	uddata, err := params.Get(VarReqUserDefinedMetadata)
@@ -136,14 +182,16 @@ func mintSupply(ctx coretypes.Sandbox) error {
 	}
 	// create the metadata record and marshal it into binary
 	senderAddress := ctx.Caller()
-	rec := &TokenMetadata{
-		Supply:      supply,
-		MintedBy:    senderAddress,
-		Owner:       senderAddress,
-		Created:     ctx.GetTimestamp(),
-		Updated:     ctx.GetTimestamp(),
-		Description: description,
-		UserDefined: uddata,
+	rec, err := NewTokenMetadataBuilder().
+		Supply(supply).
+		MintedBy(senderAddress).
+		Owner(senderAddress).
+		Timestamp(ctx.GetTimestamp()).
+		Description(description).
+		UserDefined(uddata).
+		Build()
+	if err != nil {
+		return fmt.Errorf("TokenRegistry: %v", err)
 	}
 	data, err := util.Bytes(rec)
 	if err != nil {
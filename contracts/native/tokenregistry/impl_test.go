@@ -0,0 +1,34 @@
+package tokenregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEntryPoint(t *testing.T) {
+	require.Len(t, EntryPointNames, len(EntryPoints))
+	for name, code := range EntryPoints {
+		require.EqualValues(t, name, EntryPointNames[code])
+
+		parsed, err := ParseEntryPoint(name)
+		require.NoError(t, err)
+		require.EqualValues(t, code, parsed)
+
+		parsed, err = ParseEntryPoint(code.String())
+		require.NoError(t, err)
+		require.EqualValues(t, code, parsed)
+	}
+	require.EqualValues(t, RequestMintSupply, EntryPoints["mintSupply"])
+
+	// the processor implements exactly the named entry points
+	require.Len(t, entryPoints, len(EntryPoints))
+	for code := range entryPoints {
+		require.Contains(t, EntryPointNames, code)
+	}
+
+	_, err := ParseEntryPoint("burnSupply")
+	require.Error(t, err)
+	_, err = ParseEntryPoint("00000000")
+	require.Error(t, err)
+}
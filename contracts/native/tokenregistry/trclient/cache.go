@@ -0,0 +1,100 @@
+package trclient
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/contracts/native/tokenregistry"
+)
+
+// metadataCache is an LRU cache of the registry entries by color. Entries expire ttl after they were fetched.
+// The entries are cloned in and out, so callers can't change the cached ones
+type metadataCache struct {
+	mutex   sync.Mutex
+	size    int
+	ttl     time.Duration
+	lru     *list.List // of *cacheEntry, most recently used first
+	entries map[balance.Color]*list.Element
+}
+
+type cacheEntry struct {
+	color     balance.Color
+	tm        *tokenregistry.TokenMetadata
+	fetchedAt time.Time
+}
+
+func newMetadataCache(size int, ttl time.Duration) *metadataCache {
+	return &metadataCache{
+		size:    size,
+		ttl:     ttl,
+		lru:     list.New(),
+		entries: make(map[balance.Color]*list.Element),
+	}
+}
+
+func (c *metadataCache) get(color balance.Color) (*tokenregistry.TokenMetadata, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.entries[color]
+	if !ok {
+		return nil, false
+	}
+	e := elem.Value.(*cacheEntry)
+	if time.Since(e.fetchedAt) > c.ttl {
+		c.lru.Remove(elem)
+		delete(c.entries, color)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return e.tm.Clone(), true
+}
+
+func (c *metadataCache) put(color balance.Color, tm *tokenregistry.TokenMetadata) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	tm = tm.Clone()
+
+	if elem, ok := c.entries[color]; ok {
+		elem.Value = &cacheEntry{color: color, tm: tm, fetchedAt: time.Now()}
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[color] = c.lru.PushFront(&cacheEntry{color: color, tm: tm, fetchedAt: time.Now()})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).color)
+	}
+}
+
+func (c *metadataCache) invalidate(color balance.Color) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.entries[color]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, color)
+	}
+}
+
+// WithCache makes Query cache up to size registry entries for ttl. Cache misses and colors not in the registry
+// are fetched from the node. size <= 0 disables the cache
+func (trc *TokenRegistryClient) WithCache(size int, ttl time.Duration) *TokenRegistryClient {
+	if size <= 0 {
+		trc.cache = nil
+		return trc
+	}
+	trc.cache = newMetadataCache(size, ttl)
+	return trc
+}
+
+// InvalidateColor removes the color from the cache, e.g. when the registry entry is known to be updated
+func (trc *TokenRegistryClient) InvalidateColor(color balance.Color) {
+	if trc.cache != nil {
+		trc.cache.invalidate(color)
+	}
+}
@@ -0,0 +1,59 @@
+package trclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/contracts/native/tokenregistry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataCache(t *testing.T) {
+	c := newMetadataCache(2, time.Minute)
+	tm1 := &tokenregistry.TokenMetadata{Supply: 1}
+	tm2 := &tokenregistry.TokenMetadata{Supply: 2}
+	tm3 := &tokenregistry.TokenMetadata{Supply: 3}
+
+	c.put(balance.Color{1}, tm1)
+	c.put(balance.Color{2}, tm2)
+	tm, ok := c.get(balance.Color{1})
+	require.True(t, ok)
+	require.Equal(t, tm1, tm)
+
+	// color 2 is the least recently used
+	c.put(balance.Color{3}, tm3)
+	_, ok = c.get(balance.Color{2})
+	require.False(t, ok)
+	_, ok = c.get(balance.Color{1})
+	require.True(t, ok)
+
+	c.invalidate(balance.Color{1})
+	_, ok = c.get(balance.Color{1})
+	require.False(t, ok)
+
+	c = newMetadataCache(2, time.Nanosecond)
+	c.put(balance.Color{1}, tm1)
+	time.Sleep(time.Millisecond)
+	_, ok = c.get(balance.Color{1})
+	require.False(t, ok)
+}
+
+func TestMetadataCacheCopies(t *testing.T) {
+	cache := newMetadataCache(10, time.Hour)
+	color := balance.Color{1}
+	tm := &tokenregistry.TokenMetadata{Supply: 10, Description: "token", UserDefined: []byte{1, 2}}
+	cache.put(color, tm)
+
+	// changing the stored or the returned entry doesn't change the cached one
+	tm.Description = "changed"
+	tm.UserDefined[0] = 9
+	got, ok := cache.get(color)
+	require.True(t, ok)
+	require.Equal(t, "token", got.Description)
+	require.Equal(t, []byte{1, 2}, got.UserDefined)
+	got.UserDefined[0] = 9
+	got, ok = cache.get(color)
+	require.True(t, ok)
+	require.Equal(t, []byte{1, 2}, got.UserDefined)
+}
@@ -1,57 +1,734 @@
-// +build ignore
-
 package trclient
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/client"
 	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/client/level1"
+	"github.com/iotaledger/wasp/client/scclient"
+	"github.com/iotaledger/wasp/contracts/native/tokenregistry"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/sctransaction"
-	"github.com/iotaledger/wasp/packages/vm/examples/tokenregistry"
+	"github.com/iotaledger/wasp/packages/subscribe"
+	"github.com/iotaledger/wasp/packages/txutil"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
 	"github.com/iotaledger/wasp/packages/webapi/model/statequery"
 )
 
 type TokenRegistryClient struct {
 	*chainclient.Client
-	contractHname coretypes.Hname
+	// scClient builds and posts the requests to the contract
+	scClient *scclient.SCClient
+	// KeyFunc derives the key of the registry entry from the color. DefaultKeyFunc by default
+	KeyFunc KeyFunc
+	// QueryTimeout bounds each state query to the node and each query of the balance of the chain.
+	// DefaultQueryTimeout by default, 0 means no timeout
+	QueryTimeout time.Duration
+	// ConfirmationEvent is the kind of the event published by the nodes when the request is processed.
+	// It is followed by the chain ID, the transaction ID and the request index, as in the 'request_out' event.
+	// subscribe.EventRequestOut by default
+	ConfirmationEvent string
+	// MintEntryPoint is the entry point called by MintAndRegister, e.g. of a contract extending TokenRegistry.
+	// tokenregistry.RequestMintSupply by default
+	MintEntryPoint coretypes.Hname
+	// IdempotencyWindow is how long MintAndRegister refuses to resubmit a mint with the same IdempotencyKey.
+	// DefaultIdempotencyWindow by default
+	IdempotencyWindow time.Duration
+	// PublisherHosts are the "host:port" of the publishers of the 'state' events used by SyncRegistry
+	PublisherHosts []string
+	// ReaderSigScheme, if not nil, signs the state queries of the client (see WaspClient.StateQuerySigned),
+	// for nodes which allow only the agents in webapi.stateReaders to read the state
+	ReaderSigScheme signaturescheme.SignatureScheme
+
+	mutex         sync.Mutex
+	closed        bool
+	subscriptions map[*subscribe.Subscription]struct{}
+	mints         map[string]*idempotentMint
+	cache         *metadataCache // nil if not enabled with WithCache
+}
+
+// ErrClientClosed is returned by the calls which need a subscription after the client is closed
+var ErrClientClosed = errors.New("token registry client is closed")
+
+// ErrSupplyMismatch is returned by MintAndRegister with VerifySupply when a mint target doesn't hold
+// the requested amount of the new color. The mint transaction is returned along with it
+var ErrSupplyMismatch = errors.New("minted supply mismatch")
+
+// DefaultQueryTimeout is the default timeout of the state queries of TokenRegistryClient
+const DefaultQueryTimeout = 15 * time.Second
+
+// KeyFunc derives the key of the registry entry from the color. The color must be the suffix of the key
+type KeyFunc func(color balance.Color) []byte
+
+// DefaultKeyFunc is the key layout of the TokenRegistry contract: the registry is keyed by the color bytes
+func DefaultKeyFunc(color balance.Color) []byte {
+	return color.Bytes()
 }
 
 func NewClient(scClient *chainclient.Client, contractHname coretypes.Hname) *TokenRegistryClient {
-	return &TokenRegistryClient{scClient, contractHname}
+	return &TokenRegistryClient{
+		Client:            scClient,
+		scClient:          scclient.New(scClient, contractHname),
+		KeyFunc:           DefaultKeyFunc,
+		QueryTimeout:      DefaultQueryTimeout,
+		ConfirmationEvent: subscribe.EventRequestOut,
+		MintEntryPoint:    tokenregistry.RequestMintSupply,
+		IdempotencyWindow: DefaultIdempotencyWindow,
+		subscriptions:     make(map[*subscribe.Subscription]struct{}),
+		mints:             make(map[string]*idempotentMint),
+	}
+}
+
+func (trc *TokenRegistryClient) confirmationEvent() string {
+	if trc.ConfirmationEvent == "" {
+		return subscribe.EventRequestOut
+	}
+	return trc.ConfirmationEvent
+}
+
+func (trc *TokenRegistryClient) mintEntryPoint() coretypes.Hname {
+	if trc.MintEntryPoint == 0 {
+		return tokenregistry.RequestMintSupply
+	}
+	return trc.MintEntryPoint
+}
+
+// Close closes the open subscriptions and releases the connections to the node.
+// It is safe to call it more than once
+func (trc *TokenRegistryClient) Close() error {
+	trc.mutex.Lock()
+	defer trc.mutex.Unlock()
+
+	if trc.closed {
+		return nil
+	}
+	trc.closed = true
+	for subs := range trc.subscriptions {
+		subs.Close()
+	}
+	trc.subscriptions = nil
+	if trc.WaspClient != nil {
+		trc.WaspClient.Close()
+	}
+	return nil
+}
+
+// addSubscription registers the subscription to be closed by Close. If the client is already closed,
+// the subscription is closed right away
+func (trc *TokenRegistryClient) addSubscription(subs *subscribe.Subscription) error {
+	trc.mutex.Lock()
+	defer trc.mutex.Unlock()
+
+	if trc.closed {
+		subs.Close()
+		return ErrClientClosed
+	}
+	if trc.subscriptions == nil {
+		trc.subscriptions = make(map[*subscribe.Subscription]struct{})
+	}
+	trc.subscriptions[subs] = struct{}{}
+	return nil
+}
+
+// closeSubscription closes the subscription and removes it from the client
+func (trc *TokenRegistryClient) closeSubscription(subs *subscribe.Subscription) {
+	trc.mutex.Lock()
+	defer trc.mutex.Unlock()
+
+	delete(trc.subscriptions, subs)
+	subs.Close()
+}
+
+// queryContext returns the context bounding one state query by QueryTimeout. It is done when the parent is done
+func (trc *TokenRegistryClient) queryContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if trc.QueryTimeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, trc.QueryTimeout)
+}
+
+func (trc *TokenRegistryClient) stateQuery(query *statequery.Request) (*statequery.Results, error) {
+	return trc.stateQueryCtx(context.Background(), query)
+}
+
+func (trc *TokenRegistryClient) stateQueryCtx(ctx context.Context, query *statequery.Request) (*statequery.Results, error) {
+	ctx, cancel := trc.queryContext(ctx)
+	defer cancel()
+	if trc.ReaderSigScheme != nil {
+		return trc.WaspClient.StateQuerySignedCtx(ctx, &trc.ChainID, query, trc.ReaderSigScheme)
+	}
+	return trc.StateQueryCtx(ctx, query)
+}
+
+func (trc *TokenRegistryClient) keyFunc() KeyFunc {
+	if trc.KeyFunc == nil {
+		return DefaultKeyFunc
+	}
+	return trc.KeyFunc
+}
+
+// colorFromKey takes the color from the end of the key and checks the key matches keyFunc
+func colorFromKey(keyFunc KeyFunc, key []byte) (balance.Color, error) {
+	var color balance.Color
+	if len(key) < balance.ColorLength {
+		return color, fmt.Errorf("invalid registry key %x", key)
+	}
+	copy(color[:], key[len(key)-balance.ColorLength:])
+	if !bytes.Equal(keyFunc(color), key) {
+		return color, fmt.Errorf("registry key %x doesn't match the key function", key)
+	}
+	return color, nil
+}
+
+// NewClientWithWaspClient creates the TokenRegistry client which sends its webapi requests through
+// the given, already configured, WaspClient (timeouts, headers, auth)
+func NewClientWithWaspClient(
+	level1Client level1.Level1Client,
+	waspClient *client.WaspClient,
+	chainID coretypes.ChainID,
+	sigScheme signaturescheme.SignatureScheme,
+	contractHname coretypes.Hname,
+) *TokenRegistryClient {
+	return NewClient(chainclient.New(level1Client, waspClient, chainID, sigScheme), contractHname)
 }
 
+// NewClientFromHost creates the TokenRegistry client with a default WaspClient for the given host
+func NewClientFromHost(
+	level1Client level1.Level1Client,
+	waspHost string,
+	chainID coretypes.ChainID,
+	sigScheme signaturescheme.SignatureScheme,
+	contractHname coretypes.Hname,
+) *TokenRegistryClient {
+	return NewClientWithWaspClient(level1Client, client.NewWaspClient(waspHost), chainID, sigScheme, contractHname)
+}
+
+// ConfirmationStrategy selects how MintAndRegister waits for the request to be processed,
+// see chainclient.ConfirmationStrategy
+type ConfirmationStrategy = chainclient.ConfirmationStrategy
+
+const (
+	ConfirmBoth      = chainclient.ConfirmBoth
+	ConfirmPoll      = chainclient.ConfirmPoll
+	ConfirmSubscribe = chainclient.ConfirmSubscribe
+)
+
 type MintAndRegisterParams struct {
-	Supply          int64           // number of tokens to mint
-	MintTarget      address.Address // where to mint new Supply
-	Description     string
-	UserDefinedData []byte
+	Supply            int64           // number of tokens to mint
+	MintTarget        address.Address // where to mint new Supply. The zero address means the owner address of the client
+	Description       string
+	UserDefinedData   []byte
+	WaitForCompletion bool
+	PublisherHosts    []string // "host:port" of the publishers. Validated and deduplicated with subscribe.NewHostSet
+	PublisherQuorum   int      // between 0 and the number of the deduplicated PublisherHosts
+	Timeout           time.Duration
+	Confirmation      ConfirmationStrategy // ConfirmBoth by default
+	ExtraArgs         dict.Dict            // additional arguments for extended registry contracts
+	VerifySupply      bool                 // check the minted balance after completion. ErrSupplyMismatch if it differs
+	Sign              SignFunc             // nil means signing with the SigScheme of the client
+	// if not zero, the contract rejects the request if it is processed after the deadline.
+	// Must be in the future
+	Deadline time.Time
+	// if not nil, MintAndRegister fills it with the durations of its phases
+	Timing *MintTiming
+	// if not empty, a mint with the same key is not resubmitted by the client within its IdempotencyWindow,
+	// e.g. when retrying after a timeout. See ErrDuplicateMint
+	IdempotencyKey string
+	// if not nil and WaitForCompletion is false, MintAndRegister fills it when the transaction is posted,
+	// e.g. to key the UI state of the mint before it is confirmed
+	Pending *PendingMint
+	// if not empty, the Supply is split among the addresses instead of minting it to MintTarget,
+	// e.g. for an airdrop. The amounts must sum to Supply
+	MintTargets map[address.Address]int64
+	// if not nil, it is called when MintAndRegister reaches each of the stages (ProgressBuilt, ProgressSigned etc),
+	// e.g. to show the status in a CLI. It must not block
+	OnProgress func(stage string)
+	// if not nil, the fee is transferred to the chain together with the request, for chains which charge
+	// a processing fee in a specific color. The wallet must hold it
+	Fee *Fee
+}
+
+// Fee is the processing fee transferred to the chain with the request
+type Fee struct {
+	Color  balance.Color
+	Amount int64
+}
+
+// stages of MintAndRegister reported to MintAndRegisterParams.OnProgress
+const (
+	ProgressBuilt      = "built"  // the transaction is built
+	ProgressSigned     = "signed" // the transaction is signed
+	ProgressPosted     = chainclient.ProgressPosted
+	ProgressConfirmed  = chainclient.ProgressConfirmed
+	ProgressRegistered = chainclient.ProgressRegistered
+)
+
+func (par *MintAndRegisterParams) progress(stage string) {
+	if par.OnProgress != nil {
+		par.OnProgress(stage)
+	}
+}
+
+// PendingMint is the client-side handle of a posted mint. All fields are known before the request is processed.
+// A posted mint can't be cancelled by the client: the request tokens are sent to the chain address,
+// so only the committee of the chain can spend them. Use MintAndRegisterParams.Deadline to make the contract
+// reject a request which is processed too late
+type PendingMint struct {
+	TxID        valuetransaction.ID
+	Color       balance.Color // the color of the new supply, which is the ID of the transaction
+	SubmittedAt time.Time
+}
+
+// NewPendingMint creates the handle of the mint posted in the transaction at the given time
+func NewPendingMint(tx *sctransaction.Transaction, submittedAt time.Time) *PendingMint {
+	return &PendingMint{
+		TxID:        tx.ID(),
+		Color:       (balance.Color)(tx.ID()),
+		SubmittedAt: submittedAt,
+	}
+}
+
+// RequestID returns the ID of the mintSupply request, e.g. to poll its status
+func (p *PendingMint) RequestID() coretypes.RequestID {
+	return sctransaction.RequestID(p.TxID, 0)
+}
+
+// PendingMints returns the mints posted to the contract and not processed yet by the committee,
+// in the order they were received by the node. The node must be in the committee of the chain,
+// otherwise the error is a 409 Conflict HTTP error
+func (trc *TokenRegistryClient) PendingMints() ([]*PendingMint, error) {
+	pending, err := trc.WaspClient.GetPendingRequests(trc.ChainID, trc.scClient.ContractHname)
+	if err != nil {
+		return nil, err
+	}
+	mintEntryPoint := trc.mintEntryPoint().String()
+	ret := make([]*PendingMint, 0)
+	for _, req := range pending {
+		if req.EntryPoint != mintEntryPoint {
+			continue
+		}
+		reqID, err := coretypes.NewRequestIDFromBase58(req.RequestID)
+		if err != nil {
+			return nil, err
+		}
+		txID := *reqID.TransactionID()
+		ret = append(ret, &PendingMint{
+			TxID:        txID,
+			Color:       (balance.Color)(txID),
+			SubmittedAt: req.WhenReceived,
+		})
+	}
+	return ret, nil
+}
+
+// IsMintConfirmed checks if the mint transaction is confirmed in the ledger, without waiting for it.
+// The request may still be not processed by the chain
+func (trc *TokenRegistryClient) IsMintConfirmed(tx *sctransaction.Transaction) (bool, error) {
+	return level1.IsConfirmed(trc.Level1Client, tx.ID())
+}
+
+// MintTiming is the latency breakdown of MintAndRegister
+type MintTiming struct {
+	Build time.Duration // building and signing the transaction
+	// posting the transaction. It includes waiting for the ledger confirmation with ConfirmPoll and ConfirmBoth
+	Post time.Duration
+	// from the start of posting until the first 'request_out' event. 0 with ConfirmPoll
+	FirstEvent time.Duration
+	// from the start of posting until the request is confirmed as processed
+	Confirmation time.Duration
+}
+
+// SignFunc adds signatures to the transaction before it is posted, e.g. collecting signatures
+// of all owners of a custodial wallet
+type SignFunc func(tx *sctransaction.Transaction) error
+
+// SignWith returns SignFunc which signs the transaction with each of the signature schemes
+func SignWith(sigSchemes ...signaturescheme.SignatureScheme) SignFunc {
+	return func(tx *sctransaction.Transaction) error {
+		if len(sigSchemes) == 0 {
+			return fmt.Errorf("no signature schemes provided")
+		}
+		for _, sigScheme := range sigSchemes {
+			tx.Sign(sigScheme)
+		}
+		return nil
+	}
 }
 
+// reservedArgs are request arguments set by MintAndRegister itself
+var reservedArgs = []kv.Key{tokenregistry.VarReqDescription, tokenregistry.VarReqUserDefinedMetadata, tokenregistry.VarReqDeadline}
+
 func (trc *TokenRegistryClient) OwnerAddress() address.Address {
 	return trc.SigScheme.Address()
 }
 
+// FetchBalances returns colored balances of the owner address and of the chain address, fetched in one call
+func (trc *TokenRegistryClient) FetchBalances() (owner map[balance.Color]int64, chain map[balance.Color]int64, err error) {
+	ownerAddr := trc.OwnerAddress()
+	chainAddr := (address.Address)(trc.ChainID)
+	outs, err := level1.GetConfirmedAccountOutputsMulti(trc.Level1Client, []*address.Address{&ownerAddr, &chainAddr})
+	if err != nil {
+		return nil, nil, err
+	}
+	owner, _ = txutil.OutputBalancesByColor(outs[ownerAddr])
+	chain, _ = txutil.OutputBalancesByColor(outs[chainAddr])
+	return owner, chain, nil
+}
+
+// ContractID returns the ID of the TokenRegistry contract on the chain
+func (trc *TokenRegistryClient) ContractID() coretypes.ContractID {
+	return coretypes.NewContractID(trc.ChainID, trc.scClient.ContractHname)
+}
+
+// ContractAgentID returns the contract-type AgentID of the TokenRegistry contract,
+// e.g. to be used in access control arguments
+func (trc *TokenRegistryClient) ContractAgentID() coretypes.AgentID {
+	return coretypes.NewAgentIDFromContractID(trc.ContractID())
+}
+
+// VerifyContract checks in the contract registry of the chain that the contract with the hname of the client
+// is deployed and is an instance of the TokenRegistry program. An existing registry can't be told from
+// an empty one by its state, so the program hash is checked instead
+func (trc *TokenRegistryClient) VerifyContract() error {
+	args := dict.New()
+	args.Set(root.ParamHname, codec.EncodeHname(trc.scClient.ContractHname))
+	ret, err := trc.CallView(root.Interface.Hname(), root.FuncFindContract, args)
+	if err != nil {
+		return fmt.Errorf("contract %s not found: %w", trc.ContractID(), err)
+	}
+	recBin, err := ret.Get(root.ParamData)
+	if err != nil {
+		return err
+	}
+	rec, err := root.DecodeContractRecord(recBin)
+	if err != nil {
+		return err
+	}
+	progHash, err := hashing.HashValueFromBase58(tokenregistry.ProgramHash)
+	if err != nil {
+		return err
+	}
+	if rec.ProgramHash != progHash {
+		return fmt.Errorf("contract %s is not a TokenRegistry: program hash %s", trc.ContractID(), rec.ProgramHash.String())
+	}
+	return nil
+}
+
 // MintAndRegister mints new Supply of colored tokens to some address and sends request
-// to register it in the TokenRegistry smart contract
+// to register it in the TokenRegistry smart contract.
+// The transaction is built and signed before anything is posted, so its ID (the color of the new supply)
+// is known in advance.
+// If par.Timeout is not 0, it bounds the whole call, including building, posting and waiting for completion.
+// If it expires while the transaction is being posted, the mint may still happen: chainclient.OutcomeUnknownError
+// is returned with the transaction, and the mint must not be retried before IsMintConfirmed of the transaction
+// is checked. If the transaction is posted but waiting for its processing fails, it is returned with the error too.
+// With par.IdempotencyKey, a retry with the same key returns the same transaction with ErrDuplicateMint,
+// unless the previous call failed without posting anything.
+// The supply of an already registered color can't be increased: the ledger colors newly minted tokens
+// (balance.ColorNew) with the ID of the minting transaction, so every mint creates a new color
 func (trc *TokenRegistryClient) MintAndRegister(par MintAndRegisterParams) (*sctransaction.Transaction, error) {
+	if par.MintTarget == (address.Address{}) && len(par.MintTargets) == 0 {
+		par.MintTarget = trc.OwnerAddress()
+	}
+	if par.WaitForCompletion && par.Confirmation != ConfirmPoll {
+		// malformed hosts are reported before anything is built or posted
+		hosts, err := subscribe.NewHostSet(par.PublisherHosts...)
+		if err != nil {
+			return nil, err
+		}
+		if err := hosts.CheckQuorum(par.PublisherQuorum); err != nil {
+			return nil, err
+		}
+		par.PublisherHosts = hosts
+	}
+	ctx := context.Background()
+	if par.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, par.Timeout)
+		defer cancel()
+	}
+	if par.Timing == nil {
+		par.Timing = &MintTiming{}
+	}
+	if par.IdempotencyKey != "" {
+		if prevTx, err := trc.beginIdempotentMint(par.IdempotencyKey); err != nil {
+			return prevTx, err
+		}
+	}
+	buildStart := time.Now()
+	var tx *sctransaction.Transaction
+	err := util.RunWithContext(ctx, func() error {
+		var err error
+		tx, err = trc.buildMintTx(par)
+		return err
+	})
+	if err != nil {
+		if par.IdempotencyKey != "" {
+			// nothing was posted, the mint can be retried
+			trc.abortIdempotentMint(par.IdempotencyKey)
+		}
+		return nil, err
+	}
+	if par.IdempotencyKey != "" {
+		trc.setIdempotentMintTx(par.IdempotencyKey, tx)
+	}
+	par.Timing.Build = time.Since(buildStart)
+	if !par.WaitForCompletion {
+		postStart := time.Now()
+		if err = chainclient.PostCtx(ctx, tx, trc.Level1Client.PostTransaction); err != nil {
+			return trc.postFailed(tx, err, par.IdempotencyKey)
+		}
+		par.Timing.Post = time.Since(postStart)
+		par.progress(ProgressPosted)
+		if par.Pending != nil {
+			*par.Pending = *NewPendingMint(tx, postStart)
+		}
+		return tx, nil
+	}
+	posted, err := trc.postAndWaitForConfirmation(ctx, tx, par)
+	if err != nil {
+		if posted {
+			// the mint is posted but its processing is not confirmed: the transaction is needed to check it
+			return tx, err
+		}
+		return trc.postFailed(tx, err, par.IdempotencyKey)
+	}
+	if par.VerifySupply {
+		err = util.RunWithContext(ctx, func() error {
+			return trc.verifySupply(tx, par)
+		})
+		if err != nil {
+			// the mint is posted and processed: the transaction is needed to examine the mismatch
+			return tx, err
+		}
+	}
+	return tx, nil
+}
+
+// postFailed returns the transaction with the error if it may have been posted, so it can be checked.
+// Otherwise nothing was posted, and the mint can be retried with the same idempotency key
+func (trc *TokenRegistryClient) postFailed(tx *sctransaction.Transaction, err error, idempotencyKey string) (*sctransaction.Transaction, error) {
+	if errors.Is(err, chainclient.ErrOutcomeUnknown) {
+		return tx, err
+	}
+	if idempotencyKey != "" {
+		trc.abortIdempotentMint(idempotencyKey)
+	}
+	return nil, err
+}
+
+// buildMintTx builds and signs the mintSupply request transaction
+func (trc *TokenRegistryClient) buildMintTx(par MintAndRegisterParams) (*sctransaction.Transaction, error) {
+	reqPar, err := trc.mintRequestParams(par)
+	if err != nil {
+		return nil, err
+	}
+	if par.OnProgress != nil {
+		sign := par.Sign
+		if sign == nil {
+			sign = SignWith(trc.SigScheme)
+		}
+		par.Sign = func(tx *sctransaction.Transaction) error {
+			par.progress(ProgressBuilt)
+			if err := sign(tx); err != nil {
+				return err
+			}
+			par.progress(ProgressSigned)
+			return nil
+		}
+	}
+	reqPar.Sign = par.Sign
+	return trc.scClient.BuildRequest(trc.mintEntryPoint(), *reqPar)
+}
+
+// BuildUnsigned builds the mintSupply request transaction MintAndRegister would post, without signing it,
+// e.g. to examine its structure before the signatures. Nothing is posted
+func (trc *TokenRegistryClient) BuildUnsigned(par MintAndRegisterParams) (*sctransaction.Transaction, error) {
+	if par.MintTarget == (address.Address{}) && len(par.MintTargets) == 0 {
+		par.MintTarget = trc.OwnerAddress()
+	}
+	reqPar, err := trc.mintRequestParams(par)
+	if err != nil {
+		return nil, err
+	}
+	return trc.scClient.BuildRequestUnsigned(trc.mintEntryPoint(), *reqPar)
+}
+
+// mintRequestParams returns the parameters of the mintSupply request transaction, without the signing function.
+// The contract and the entry point are set by the scclient.SCClient
+func (trc *TokenRegistryClient) mintRequestParams(par MintAndRegisterParams) (*chainclient.RequestParams, error) {
+	args, err := makeMintArgs(par)
+	if err != nil {
+		return nil, err
+	}
+	mint, err := mintTargets(par)
+	if err != nil {
+		return nil, err
+	}
+	var transfer coretypes.ColoredBalances
+	if par.Fee != nil {
+		if err := trc.checkFee(par.Fee); err != nil {
+			return nil, err
+		}
+		transfer = cbalances.NewFromMap(map[balance.Color]int64{par.Fee.Color: par.Fee.Amount})
+	}
+	return &chainclient.RequestParams{
+		Args:     requestargs.New().AddEncodeSimpleMany(args),
+		Transfer: transfer,
+		Mint:     mint,
+	}, nil
+}
+
+// subscribeMulti is replaced in tests to inject publisher events
+var subscribeMulti = subscribe.SubscribeMulti
+
+// checkFee checks the fee is positive and the owner address of the client holds it
+func (trc *TokenRegistryClient) checkFee(fee *Fee) error {
+	if fee.Amount <= 0 {
+		return fmt.Errorf("fee must be > 0, got %d", fee.Amount)
+	}
+	if fee.Color == balance.ColorNew {
+		return fmt.Errorf("fee can't be paid in new tokens")
+	}
+	ownerAddr := trc.OwnerAddress()
+	outs, err := trc.Level1Client.GetConfirmedAccountOutputs(&ownerAddr)
+	if err != nil {
+		return err
+	}
+	bals, _ := txutil.OutputBalancesByColor(outs)
+	if bals[fee.Color] < fee.Amount {
+		return fmt.Errorf("not enough tokens of color %s for the fee: needed %d, the wallet holds %d",
+			fee.Color.String(), fee.Amount, bals[fee.Color])
+	}
+	return nil
+}
+
+// mintTargets returns the amounts of the new supply to mint to each address:
+// par.MintTargets if not empty, otherwise the whole Supply to par.MintTarget
+func mintTargets(par MintAndRegisterParams) (map[address.Address]int64, error) {
+	if len(par.MintTargets) == 0 {
+		return map[address.Address]int64{par.MintTarget: par.Supply}, nil
+	}
+	if par.MintTarget != (address.Address{}) {
+		return nil, fmt.Errorf("MintTarget and MintTargets can't be used together")
+	}
+	sum := int64(0)
+	for addr, amount := range par.MintTargets {
+		if amount <= 0 {
+			return nil, fmt.Errorf("amount to mint to %s must be > 0, got %d", addr.String(), amount)
+		}
+		sum += amount
+	}
+	if sum != par.Supply {
+		return nil, fmt.Errorf("amounts of MintTargets sum to %d, must be equal to Supply %d", sum, par.Supply)
+	}
+	return par.MintTargets, nil
+}
+
+// verifySupply checks that each mint target holds exactly the requested amount of the new color,
+// otherwise it returns ErrSupplyMismatch
+func (trc *TokenRegistryClient) verifySupply(tx *sctransaction.Transaction, par MintAndRegisterParams) error {
+	color := (balance.Color)(tx.ID())
+	mint, err := mintTargets(par)
+	if err != nil {
+		return err
+	}
+	for addr, amount := range mint {
+		addr := addr
+		outs, err := trc.Level1Client.GetConfirmedAccountOutputs(&addr)
+		if err != nil {
+			return err
+		}
+		bals, _ := txutil.OutputBalancesByColor(outs)
+		if bals[color] != amount {
+			return fmt.Errorf("%w: color %s to %s: requested %d, found %d",
+				ErrSupplyMismatch, color.String(), addr.String(), amount, bals[color])
+		}
+	}
+	return nil
+}
+
+// makeMintArgs builds the arguments of the mintSupply request, merging par.ExtraArgs
+func makeMintArgs(par MintAndRegisterParams) (dict.Dict, error) {
 	args := make(map[string]interface{})
 	args[tokenregistry.VarReqDescription] = par.Description
 	if par.UserDefinedData != nil {
 		args[tokenregistry.VarReqUserDefinedMetadata] = par.UserDefinedData
 	}
-	return trc.PostRequest(
-		trc.contractHname,
-		tokenregistry.RequestMintSupply,
-		chainclient.PostRequestParams{
-			Mint:    map[address.Address]int64{par.MintTarget: par.Supply},
-			ArgsRaw: codec.MakeDict(args),
+	if !par.Deadline.IsZero() {
+		if !par.Deadline.After(time.Now()) {
+			return nil, fmt.Errorf("deadline %v is not in the future", par.Deadline)
+		}
+		args[tokenregistry.VarReqDeadline] = par.Deadline.UnixNano()
+	}
+	ret := codec.MakeDict(args)
+	for k, v := range par.ExtraArgs {
+		for _, r := range reservedArgs {
+			if k == r {
+				return nil, fmt.Errorf("extra argument '%s' conflicts with a reserved argument", k)
+			}
+		}
+		ret.Set(k, v)
+	}
+	return ret, nil
+}
+
+// postAndWaitForConfirmation posts the transaction and waits for the request to be processed
+// using the mechanism selected by par.Confirmation. The context bounds all the calls
+// postAndWaitForConfirmation returns if the transaction was posted, also when waiting for the confirmation fails
+func (trc *TokenRegistryClient) postAndWaitForConfirmation(ctx context.Context, tx *sctransaction.Transaction, par MintAndRegisterParams) (bool, error) {
+	posted := false
+	timing, err := trc.PostAndWaitForConfirmation(ctx, tx, chainclient.ConfirmParams{
+		Confirmation:    par.Confirmation,
+		PublisherHosts:  par.PublisherHosts,
+		PublisherQuorum: par.PublisherQuorum,
+		Timeout:         par.Timeout,
+		Event:           trc.confirmationEvent(),
+		Subscribe: func(hosts []string, topics []string, quorum ...int) (*subscribe.Subscription, error) {
+			subs, err := subscribeMulti(hosts, topics, quorum...)
+			if err != nil {
+				return nil, err
+			}
+			// the subscription is closed by Close of the client if it is still open
+			if err := trc.addSubscription(subs); err != nil {
+				return nil, err
+			}
+			return subs, nil
+		},
+		Unsubscribe: trc.closeSubscription,
+		OnProgress: func(stage string) {
+			if stage == ProgressPosted {
+				posted = true
+			}
+			par.progress(stage)
 		},
-	)
+	})
+	par.Timing.Post = timing.Post
+	par.Timing.FirstEvent = timing.FirstEvent
+	par.Timing.Confirmation = timing.Confirmation
+	return posted, err
 }
 
 type Status struct {
@@ -66,23 +743,330 @@ type TokenMetadataWithColor struct {
 	Color balance.Color
 }
 
-func (trc *TokenRegistryClient) FetchStatus(sortByAgeDesc bool) (*Status, error) {
-	scStatus, results, err := trc.FetchSCStatus(func(query *statequery.Request) {
-		query.AddMap(tokenregistry.VarStateTheRegistry, 100)
+// MarshalJSON adds the base58-encoded color to the JSON representation of the metadata.
+// Otherwise the promoted TokenMetadata.MarshalJSON would omit it
+func (tm *TokenMetadataWithColor) MarshalJSON() ([]byte, error) {
+	data, err := tm.TokenMetadata.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if fields["color"], err = json.Marshal(tm.Color.String()); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// NewStatus creates a Status from known data, e.g. for tests and caching. Other fields of SCStatus are left empty
+func NewStatus(balance map[balance.Color]int64, registry map[balance.Color]*tokenregistry.TokenMetadata) *Status {
+	return &Status{
+		SCStatus: &chainclient.SCStatus{Balance: balance},
+		Registry: registry,
+	}
+}
+
+// Equal compares two snapshots of the status. FetchedAt is ignored
+func (s *Status) Equal(other *Status) bool {
+	if s == nil || other == nil {
+		return s == other
+	}
+	var sc1, sc2 chainclient.SCStatus
+	if s.SCStatus != nil {
+		sc1 = *s.SCStatus
+	}
+	if other.SCStatus != nil {
+		sc2 = *other.SCStatus
+	}
+	sc1.FetchedAt = time.Time{}
+	sc2.FetchedAt = time.Time{}
+	if !reflect.DeepEqual(sc1, sc2) {
+		return false
+	}
+	return reflect.DeepEqual(s.Registry, other.Registry)
+}
+
+// Fingerprint is a hash of the balance and of the registry, iterated in the order of colors, e.g. to detect
+// changes between snapshots. Like Equal, it ignores FetchedAt
+func (s *Status) Fingerprint() [32]byte {
+	var buf bytes.Buffer
+	var bal map[balance.Color]int64
+	if s.SCStatus != nil {
+		bal = s.SCStatus.Balance
+	}
+	colors := make([]balance.Color, 0, len(bal))
+	for col := range bal {
+		colors = append(colors, col)
+	}
+	sortColors(colors)
+	_ = util.WriteUint32(&buf, uint32(len(colors)))
+	for _, col := range colors {
+		buf.Write(col[:])
+		_ = util.WriteInt64(&buf, bal[col])
+	}
+	colors = make([]balance.Color, 0, len(s.Registry))
+	for col := range s.Registry {
+		colors = append(colors, col)
+	}
+	sortColors(colors)
+	_ = util.WriteUint32(&buf, uint32(len(colors)))
+	for _, col := range colors {
+		buf.Write(col[:])
+		tm := s.Registry[col]
+		_ = util.WriteBoolByte(&buf, tm != nil)
+		if tm != nil {
+			_ = tm.Write(&buf)
+		}
+	}
+	return hashing.HashData(buf.Bytes())
+}
+
+// StatusDiff is the difference between two snapshots of the status, e.g. to show what changed
+// since the last refresh. Colors are sorted by color bytes
+type StatusDiff struct {
+	Added   []balance.Color // colors registered since the previous snapshot
+	Removed []balance.Color // colors not in the registry anymore
+	Changed []balance.Color // colors with changed metadata
+	// non-zero changes of the balance of the contract by color
+	BalanceDeltas map[balance.Color]int64
+}
+
+// IsEmpty returns true if nothing changed between the snapshots
+func (d *StatusDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 && len(d.BalanceDeltas) == 0
+}
+
+// Diff returns the changes from the previous snapshot to s. A nil prev is treated as an empty status.
+// Like Equal, it ignores FetchedAt
+func (s *Status) Diff(prev *Status) StatusDiff {
+	if prev == nil {
+		prev = &Status{}
+	}
+	ret := StatusDiff{BalanceDeltas: make(map[balance.Color]int64)}
+	for col, tm := range s.Registry {
+		prevTm, ok := prev.Registry[col]
+		switch {
+		case !ok:
+			ret.Added = append(ret.Added, col)
+		case !reflect.DeepEqual(tm, prevTm):
+			ret.Changed = append(ret.Changed, col)
+		}
+	}
+	for col := range prev.Registry {
+		if _, ok := s.Registry[col]; !ok {
+			ret.Removed = append(ret.Removed, col)
+		}
+	}
+	sortColors(ret.Added)
+	sortColors(ret.Removed)
+	sortColors(ret.Changed)
+
+	var bal, prevBal map[balance.Color]int64
+	if s.SCStatus != nil {
+		bal = s.SCStatus.Balance
+	}
+	if prev.SCStatus != nil {
+		prevBal = prev.SCStatus.Balance
+	}
+	for col, b := range bal {
+		if delta := b - prevBal[col]; delta != 0 {
+			ret.BalanceDeltas[col] = delta
+		}
+	}
+	for col, b := range prevBal {
+		if _, ok := bal[col]; !ok && b != 0 {
+			ret.BalanceDeltas[col] = -b
+		}
+	}
+	return ret
+}
+
+// sortColors sorts colors by color bytes
+func sortColors(colors []balance.Color) {
+	sort.Slice(colors, func(i, j int) bool {
+		return bytes.Compare(colors[i][:], colors[j][:]) < 0
 	})
+}
+
+// RegistryByColorString returns the registry keyed by the string form of the color (see util.ColorToString),
+// e.g. to be serialized to JSON
+func (s *Status) RegistryByColorString() map[string]*tokenregistry.TokenMetadata {
+	ret := make(map[string]*tokenregistry.TokenMetadata, len(s.Registry))
+	for col, tm := range s.Registry {
+		ret[util.ColorToString(col)] = tm
+	}
+	return ret
+}
+
+func (trc *TokenRegistryClient) FetchStatus(sortByAgeDesc bool) (*Status, error) {
+	return trc.FetchStatusCtx(context.Background(), sortByAgeDesc)
+}
+
+// FetchStatusCtx is like FetchStatus, but the queries to the node are abandoned when the context is done
+func (trc *TokenRegistryClient) FetchStatusCtx(ctx context.Context, sortByAgeDesc bool) (*Status, error) {
+	return trc.fetchStatus(ctx, FetchStatusParams{SortByAgeDesc: sortByAgeDesc})
+}
+
+type FetchStatusParams struct {
+	SortByAgeDesc bool
+	// if true, failed sub-queries don't fail the whole call: the Status is returned with the data
+	// which was fetched, together with a *FetchStatusError listing the failed sources
+	Partial bool
+}
+
+// sources of the data of the Status, as reported in FetchStatusError
+const (
+	StatusSourceBalance  = "balance"
+	StatusSourceState    = "state"
+	StatusSourceRegistry = "registry"
+)
+
+// FetchStatusError collects the errors of the sub-queries of FetchStatusWithParams by source
+type FetchStatusError struct {
+	Errors map[string]error
+}
+
+func (e *FetchStatusError) Error() string {
+	sources := make([]string, 0, len(e.Errors))
+	for src := range e.Errors {
+		sources = append(sources, src)
+	}
+	sort.Strings(sources)
+	msgs := make([]string, len(sources))
+	for i, src := range sources {
+		msgs[i] = fmt.Sprintf("%s: %v", src, e.Errors[src])
+	}
+	return "FetchStatus failed: " + strings.Join(msgs, "; ")
+}
+
+// Failed checks if the data from the source is missing in the Status
+func (e *FetchStatusError) Failed(source string) bool {
+	_, ok := e.Errors[source]
+	return ok
+}
+
+func (trc *TokenRegistryClient) FetchStatusWithParams(par FetchStatusParams) (*Status, error) {
+	return trc.fetchStatus(context.Background(), par)
+}
+
+func (trc *TokenRegistryClient) fetchStatus(ctx context.Context, par FetchStatusParams) (*Status, error) {
+	if !par.Partial {
+		status, err := trc.fetchStatusStrict(ctx)
+		if err != nil {
+			return nil, err
+		}
+		status.sortRegistry(par.SortByAgeDesc)
+		return status, nil
+	}
+
+	errs := make(map[string]error)
+	balance, err := trc.fetchBalance(ctx)
+	if err != nil {
+		errs[StatusSourceBalance] = err
+	}
+	scStatus, registry, err := trc.fetchRegistryState(ctx)
+	if err != nil {
+		errs[StatusSourceRegistry] = err
+	}
+	if scStatus == nil {
+		errs[StatusSourceState] = err
+		scStatus = &chainclient.SCStatus{
+			SCAddress: (address.Address)(trc.ChainID),
+			FetchedAt: time.Now().UTC(),
+		}
+	}
+	scStatus.Balance = balance
+	status := &Status{SCStatus: scStatus, Registry: registry}
+	status.sortRegistry(par.SortByAgeDesc)
+	if len(errs) > 0 {
+		return status, &FetchStatusError{Errors: errs}
+	}
+	return status, nil
+}
+
+// FetchStatusFiltered is like FetchStatus, but only the balance and the metadata of the given colors are included.
+// The metadata and the balances are fetched with element queries of the colors in one state query, without
+// fetching the whole registry. The balances are the total assets on the chain, taken from the same state.
+// Colors which are not in the registry or in the balance are missing in the result
+func (trc *TokenRegistryClient) FetchStatusFiltered(colors []balance.Color) (*Status, error) {
+	return trc.FetchStatusFilteredCtx(context.Background(), colors)
+}
+
+// FetchStatusFilteredCtx is like FetchStatusFiltered, but the query to the node is abandoned when the context is done
+func (trc *TokenRegistryClient) FetchStatusFilteredCtx(ctx context.Context, colors []balance.Color) (*Status, error) {
+	totalAssets := accounts.TotalAssetsStateKey()
+	query := statequery.NewRequest()
+	query.AddGeneralData()
+	// indices of the results of the registry and balance queries of each color
+	registryQueries := make([]int, len(colors))
+	balanceQueries := make([]int, len(colors))
+	for i, color := range colors {
+		registryQueries[i] = len(query.KeyQueries)
+		query.AddMapElement(tokenregistry.VarStateTheRegistry, trc.keyFunc()(color))
+		balanceQueries[i] = len(query.KeyQueries)
+		query.AddMapElement(totalAssets, color[:])
+	}
+	res, err := trc.stateQueryCtx(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	if len(res.KeyQueryResults) != len(query.KeyQueries) {
+		return nil, fmt.Errorf("expected %d query results, got %d", len(query.KeyQueries), len(res.KeyQueryResults))
+	}
+	registryResults := make([]*statequery.QueryResult, len(colors))
+	for i := range colors {
+		registryResults[i] = res.KeyQueryResults[registryQueries[i]]
+	}
+	registry, err := decodeRegistryElements(registryResults, colors)
+	if err != nil {
+		return nil, err
+	}
+	scStatus := trc.SCStatusFromResults(res)
+	scStatus.Balance = make(map[balance.Color]int64)
+	for i, color := range colors {
+		r := res.KeyQueryResults[balanceQueries[i]]
+		if r == nil {
+			// not found
+			continue
+		}
+		value := r.MustMapElementResult()
+		if value == nil {
+			continue
+		}
+		b, err := util.Uint64From8Bytes(value)
+		if err != nil {
+			return nil, fmt.Errorf("balance of color %s: %w", color.String(), err)
+		}
+		scStatus.Balance[color] = int64(b)
+	}
+	return &Status{SCStatus: scStatus, Registry: registry}, nil
+}
 
-	status := &Status{SCStatus: scStatus}
+// fetchBalance fetches the balance of the chain, bounded by QueryTimeout
+func (trc *TokenRegistryClient) fetchBalance(ctx context.Context) (map[balance.Color]int64, error) {
+	ctx, cancel := trc.queryContext(ctx)
+	defer cancel()
+	return trc.FetchBalanceCtx(ctx)
+}
 
-	status.Registry, err = decodeRegistry(results.Get(tokenregistry.VarStateTheRegistry).MustMapResult())
+func (trc *TokenRegistryClient) fetchStatusStrict(ctx context.Context) (*Status, error) {
+	balance, err := trc.fetchBalance(ctx)
 	if err != nil {
 		return nil, err
 	}
+	scStatus, registry, err := trc.fetchRegistryState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scStatus.Balance = balance
+	return &Status{SCStatus: scStatus, Registry: registry}, nil
+}
 
-	if !sortByAgeDesc {
-		return status, nil
+func (status *Status) sortRegistry(sortByAgeDesc bool) {
+	if !sortByAgeDesc || status.Registry == nil {
+		return
 	}
 	tslice := make([]*TokenMetadataWithColor, 0, len(status.Registry))
 	for col, ti := range status.Registry {
@@ -95,16 +1079,67 @@ func (trc *TokenRegistryClient) FetchStatus(sortByAgeDesc bool) (*Status, error)
 		return tslice[i].Created > tslice[j].Created
 	})
 	status.RegistrySortedByMintTimeDesc = tslice
-	return status, nil
 }
 
-func decodeRegistry(result *statequery.MapResult) (map[balance.Color]*tokenregistry.TokenMetadata, error) {
+// ListColors returns colors of all supplies in the registry, sorted by color bytes.
+// Only the keys of the registry are queried, page by page, without the metadata
+func (trc *TokenRegistryClient) ListColors() ([]balance.Color, error) {
+	ret := make([]balance.Color, 0)
+	cursor := statequery.MapCursor{}
+	for {
+		query := statequery.NewRequest()
+		query.AddMapKeys(tokenregistry.VarStateTheRegistry, cursor, registryPageSize)
+		res, err := trc.stateQuery(query)
+		if err != nil {
+			return nil, err
+		}
+		result := res.Get(tokenregistry.VarStateTheRegistry).MustMapResult()
+		for _, e := range result.Entries {
+			color, err := colorFromKey(trc.keyFunc(), e.Key)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, color)
+		}
+		if result.Next == nil {
+			return ret, nil
+		}
+		cursor = *result.Next
+	}
+}
+
+// SearchByDescription returns the registry entries with descriptions containing substr, case-insensitively.
+// The node has no search query, so the whole registry is fetched and filtered on the client side
+func (trc *TokenRegistryClient) SearchByDescription(substr string) (map[balance.Color]*tokenregistry.TokenMetadata, error) {
+	registry, err := trc.fetchRegistry()
+	if err != nil {
+		return nil, err
+	}
+	return filterByDescription(registry, substr), nil
+}
+
+func filterByDescription(registry map[balance.Color]*tokenregistry.TokenMetadata, substr string) map[balance.Color]*tokenregistry.TokenMetadata {
+	substr = strings.ToLower(substr)
+	ret := make(map[balance.Color]*tokenregistry.TokenMetadata)
+	for col, tm := range registry {
+		if strings.Contains(strings.ToLower(tm.Description), substr) {
+			ret[col] = tm
+		}
+	}
+	return ret
+}
+
+func decodeRegistry(result *statequery.MapResult, keyFunc KeyFunc) (map[balance.Color]*tokenregistry.TokenMetadata, error) {
 	registry := make(map[balance.Color]*tokenregistry.TokenMetadata)
 	for _, e := range result.Entries {
-		color, _, err := balance.ColorFromBytes(e.Key)
+		color, err := colorFromKey(keyFunc, e.Key)
 		if err != nil {
 			return nil, err
 		}
+		if _, ok := registry[color]; ok {
+			// should not happen unless the state is corrupted. Don't hide it
+			return nil, fmt.Errorf("duplicate registry entry for color %s", color.String())
+		}
 		tm := &tokenregistry.TokenMetadata{}
 		if err := tm.Read(bytes.NewReader(e.Value)); err != nil {
 			return nil, err
@@ -114,11 +1149,23 @@ func decodeRegistry(result *statequery.MapResult) (map[balance.Color]*tokenregis
 	return registry, nil
 }
 
+// Query fetches the registry entry of the color, or nil if the color is not registered.
+// The entry is taken from the cache if it is enabled with WithCache
 func (trc *TokenRegistryClient) Query(color *balance.Color) (*tokenregistry.TokenMetadata, error) {
+	return trc.QueryCtx(context.Background(), color)
+}
+
+// QueryCtx is like Query, but the query to the node is abandoned when the context is done
+func (trc *TokenRegistryClient) QueryCtx(ctx context.Context, color *balance.Color) (*tokenregistry.TokenMetadata, error) {
+	if trc.cache != nil {
+		if tm, ok := trc.cache.get(*color); ok {
+			return tm, nil
+		}
+	}
 	query := statequery.NewRequest()
-	query.AddMapElement(tokenregistry.VarStateTheRegistry, color.Bytes())
+	query.AddMapElement(tokenregistry.VarStateTheRegistry, trc.keyFunc()(*color))
 
-	res, err := trc.StateQuery(query)
+	res, err := trc.stateQueryCtx(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -133,6 +1180,47 @@ func (trc *TokenRegistryClient) Query(color *balance.Color) (*tokenregistry.Toke
 	if err := tm.Read(bytes.NewReader(value)); err != nil {
 		return nil, err
 	}
-
+	if trc.cache != nil {
+		trc.cache.put(*color, tm)
+	}
 	return tm, nil
 }
+
+// QueryMulti fetches metadata of all colors in one state query. Colors not in the registry are absent in the result
+func (trc *TokenRegistryClient) QueryMulti(colors []balance.Color) (map[balance.Color]*tokenregistry.TokenMetadata, error) {
+	query := statequery.NewRequest()
+	for _, color := range colors {
+		query.AddMapElement(tokenregistry.VarStateTheRegistry, trc.keyFunc()(color))
+	}
+
+	res, err := trc.stateQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.KeyQueryResults) != len(colors) {
+		return nil, fmt.Errorf("expected %d query results, got %d", len(colors), len(res.KeyQueryResults))
+	}
+	return decodeRegistryElements(res.KeyQueryResults, colors)
+}
+
+// decodeRegistryElements decodes the results of the registry element queries of the colors.
+// All queries have the same key, so results are matched with colors by position, not by Results.Get
+func decodeRegistryElements(results []*statequery.QueryResult, colors []balance.Color) (map[balance.Color]*tokenregistry.TokenMetadata, error) {
+	ret := make(map[balance.Color]*tokenregistry.TokenMetadata)
+	for i, r := range results {
+		if r == nil {
+			// not found
+			continue
+		}
+		value := r.MustMapElementResult()
+		if value == nil {
+			continue
+		}
+		tm := &tokenregistry.TokenMetadata{}
+		if err := tm.Read(bytes.NewReader(value)); err != nil {
+			return nil, err
+		}
+		ret[colors[i]] = tm
+	}
+	return ret, nil
+}
@@ -0,0 +1,968 @@
+package trclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/client"
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/client/level1"
+	"github.com/iotaledger/wasp/contracts/native/tokenregistry"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/subscribe"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/packages/txutil"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/model/statequery"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeMetadata(t *testing.T, tm *tokenregistry.TokenMetadata) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, tm.Write(&buf))
+	return buf.Bytes()
+}
+
+func TestDecodeRegistryDuplicate(t *testing.T) {
+	color := balance.Color{1, 2, 3}
+	value := encodeMetadata(t, &tokenregistry.TokenMetadata{Supply: 10, Description: "first"})
+
+	result := &statequery.MapResult{
+		Len: 1,
+		Entries: []statequery.KeyValuePair{
+			{Key: color[:], Value: value},
+		},
+	}
+	registry, err := decodeRegistry(result, DefaultKeyFunc)
+	require.NoError(t, err)
+	require.Len(t, registry, 1)
+	require.EqualValues(t, "first", registry[color].Description)
+
+	result.Entries = append(result.Entries, statequery.KeyValuePair{Key: color[:], Value: value})
+	result.Len = 2
+	_, err = decodeRegistry(result, DefaultKeyFunc)
+	require.Error(t, err)
+
+	// a longer key doesn't match the key layout
+	result.Entries[1].Key = append(color.Bytes(), 0xFF)
+	_, err = decodeRegistry(result, DefaultKeyFunc)
+	require.Error(t, err)
+}
+
+func TestDecodeRegistryElements(t *testing.T) {
+	colors := []balance.Color{{1}, {2}, {3}}
+	elementResult := func(value []byte) *statequery.QueryResult {
+		var elem *statequery.MapElementResult
+		if value != nil {
+			elem = &statequery.MapElementResult{Value: value}
+		}
+		data, err := json.Marshal(elem)
+		require.NoError(t, err)
+		return &statequery.QueryResult{Type: statequery.ValueTypeMapElement, Value: data}
+	}
+	results := []*statequery.QueryResult{
+		elementResult(encodeMetadata(t, &tokenregistry.TokenMetadata{Supply: 1, Description: "first"})),
+		elementResult(nil),
+		elementResult(encodeMetadata(t, &tokenregistry.TokenMetadata{Supply: 3, Description: "third"})),
+	}
+	registry, err := decodeRegistryElements(results, colors)
+	require.NoError(t, err)
+	require.Len(t, registry, 2)
+	require.EqualValues(t, "first", registry[colors[0]].Description)
+	require.EqualValues(t, "third", registry[colors[2]].Description)
+}
+
+func TestDecodeRegistryPrefixedKeys(t *testing.T) {
+	prefixed := func(color balance.Color) []byte {
+		return append([]byte("tr:"), color.Bytes()...)
+	}
+	color := balance.Color{1, 2, 3}
+	value := encodeMetadata(t, &tokenregistry.TokenMetadata{Supply: 10, Description: "first"})
+
+	result := &statequery.MapResult{
+		Len: 1,
+		Entries: []statequery.KeyValuePair{
+			{Key: prefixed(color), Value: value},
+		},
+	}
+	registry, err := decodeRegistry(result, prefixed)
+	require.NoError(t, err)
+	require.Len(t, registry, 1)
+	require.EqualValues(t, "first", registry[color].Description)
+
+	_, err = decodeRegistry(result, DefaultKeyFunc)
+	require.Error(t, err)
+}
+
+// slowLevel1Client is a level1.Level1Client which takes 'delay' to answer each call
+type slowLevel1Client struct {
+	delay time.Duration
+}
+
+func (c *slowLevel1Client) RequestFunds(*address.Address) error {
+	time.Sleep(c.delay)
+	return nil
+}
+
+func (c *slowLevel1Client) GetConfirmedAccountOutputs(*address.Address) (map[valuetransaction.OutputID][]*balance.Balance, error) {
+	time.Sleep(c.delay)
+	return nil, nil
+}
+
+func (c *slowLevel1Client) PostTransaction(*valuetransaction.Transaction) error {
+	time.Sleep(c.delay)
+	return nil
+}
+
+func (c *slowLevel1Client) PostAndWaitForConfirmation(*valuetransaction.Transaction) error {
+	time.Sleep(c.delay)
+	return nil
+}
+
+func (c *slowLevel1Client) WaitForConfirmation(valuetransaction.ID) error {
+	time.Sleep(c.delay)
+	return nil
+}
+
+func newTestClient(level1Delay time.Duration) *TokenRegistryClient {
+	chainClient := chainclient.New(&slowLevel1Client{delay: level1Delay}, nil, coretypes.NewRandomChainID(), signaturescheme.RandBLS())
+	return NewClient(chainClient, coretypes.Hn("tokenregistry"))
+}
+
+// newFundedTestClient returns the client of a random chain with the owner address funded through level1Client
+func newFundedTestClient(t *testing.T, level1Client level1.Level1Client) *TokenRegistryClient {
+	trc := NewClient(chainclient.New(level1Client, nil, coretypes.NewRandomChainID(), signaturescheme.RandBLS()), coretypes.Hn("tokenregistry"))
+	ownerAddr := trc.OwnerAddress()
+	require.NoError(t, level1Client.RequestFunds(&ownerAddr))
+	return trc
+}
+
+// newUtxodbTestClient returns the client of a random chain backed by an in-memory UTXODB, with the owner address funded
+func newUtxodbTestClient(t *testing.T) (*TokenRegistryClient, *testutil.UtxodbLevel1Client) {
+	level1Client := testutil.NewUtxodbLevel1Client()
+	return newFundedTestClient(t, level1Client), level1Client
+}
+
+func TestMintAndRegisterTimeout(t *testing.T) {
+	trc := newTestClient(5 * time.Second)
+	timeout := 200 * time.Millisecond
+
+	start := time.Now()
+	_, err := trc.MintAndRegister(MintAndRegisterParams{
+		Supply:            1,
+		MintTarget:        trc.OwnerAddress(),
+		WaitForCompletion: true,
+		Confirmation:      ConfirmPoll,
+		Timeout:           timeout,
+	})
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Less(t, int64(time.Since(start)), int64(timeout+time.Second))
+}
+
+// slowPostLevel1Client is a UtxodbLevel1Client which takes 'delay' to post each transaction
+type slowPostLevel1Client struct {
+	*testutil.UtxodbLevel1Client
+	delay time.Duration
+}
+
+func (c *slowPostLevel1Client) PostTransaction(tx *valuetransaction.Transaction) error {
+	time.Sleep(c.delay)
+	return c.UtxodbLevel1Client.PostTransaction(tx)
+}
+
+func (c *slowPostLevel1Client) PostAndWaitForConfirmation(tx *valuetransaction.Transaction) error {
+	return c.PostTransaction(tx)
+}
+
+func TestMintAndRegisterPostTimeout(t *testing.T) {
+	trc := newFundedTestClient(t, &slowPostLevel1Client{UtxodbLevel1Client: testutil.NewUtxodbLevel1Client(), delay: time.Second})
+
+	for _, wait := range []bool{false, true} {
+		tx, err := trc.MintAndRegister(MintAndRegisterParams{
+			Supply:            1,
+			WaitForCompletion: wait,
+			Confirmation:      ConfirmPoll,
+			Timeout:           100 * time.Millisecond,
+		})
+		require.True(t, errors.Is(err, chainclient.ErrOutcomeUnknown))
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.NotNil(t, tx)
+
+		// the post completes in the background
+		require.Eventually(t, func() bool {
+			confirmed, err := trc.IsMintConfirmed(tx)
+			return err == nil && confirmed
+		}, 2*time.Second, 10*time.Millisecond)
+	}
+}
+
+func TestFetchStatusSlowBalance(t *testing.T) {
+	trc := newTestClient(10 * time.Second)
+	trc.QueryTimeout = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := trc.FetchStatus(false)
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestFetchStatusCtxCanceled(t *testing.T) {
+	trc := newTestClient(10 * time.Second)
+	trc.QueryTimeout = 0
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := trc.FetchStatusCtx(ctx, false)
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+// TestMintAndRegisterEventRightAfterPost checks that the client is subscribed before the mint is posted:
+// like on a publisher socket, the events published before subscribing are lost
+func TestMintAndRegisterEventRightAfterPost(t *testing.T) {
+	hosts := []string{"host1:5550", "host2:5550"}
+	var subsMutex sync.Mutex
+	var subs *subscribe.Subscription
+	subscribeMulti = func([]string, []string, ...int) (*subscribe.Subscription, error) {
+		subsMutex.Lock()
+		defer subsMutex.Unlock()
+		subs = subscribe.NewSubscription(hosts, []string{subscribe.EventRequestOut})
+		return subs, nil
+	}
+	defer func() { subscribeMulti = subscribe.SubscribeMulti }()
+
+	trc, level1Client := newUtxodbTestClient(t)
+	ownerAddr := trc.OwnerAddress()
+	// the nodes process the request and publish 'request_out' before PostTransaction returns
+	level1Client.OnPost = func(tx *valuetransaction.Transaction) {
+		subsMutex.Lock()
+		defer subsMutex.Unlock()
+		if subs == nil {
+			return
+		}
+		for _, host := range hosts {
+			subs.HostMessages <- &subscribe.HostMessage{
+				Sender:  host,
+				Message: append(subscribe.RequestOutPattern(trc.ChainID.String(), tx.ID().String(), 0), "1", "0", "1"),
+			}
+		}
+	}
+	timing := &MintTiming{}
+	var stages []string
+	tx, err := trc.MintAndRegister(MintAndRegisterParams{
+		Supply:            1,
+		MintTarget:        ownerAddr,
+		WaitForCompletion: true,
+		PublisherHosts:    hosts,
+		Confirmation:      ConfirmSubscribe,
+		Timeout:           time.Second,
+		Timing:            timing,
+		OnProgress: func(stage string) {
+			stages = append(stages, stage)
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, tx)
+	require.True(t, timing.FirstEvent > 0)
+	require.True(t, timing.Confirmation >= timing.FirstEvent)
+	require.Equal(t, []string{ProgressBuilt, ProgressSigned, ProgressPosted, ProgressRegistered}, stages)
+}
+
+// lostOutputsLevel1Client doesn't return the outputs of the address once it is set, as if they were spent
+type lostOutputsLevel1Client struct {
+	*testutil.UtxodbLevel1Client
+	lost *address.Address
+}
+
+func (c *lostOutputsLevel1Client) GetConfirmedAccountOutputs(addr *address.Address) (map[valuetransaction.OutputID][]*balance.Balance, error) {
+	if c.lost != nil && *c.lost == *addr {
+		return nil, nil
+	}
+	return c.UtxodbLevel1Client.GetConfirmedAccountOutputs(addr)
+}
+
+func TestMintAndRegisterSupplyMismatch(t *testing.T) {
+	hosts := []string{"host1:5550"}
+	subs := subscribe.NewSubscription(hosts, []string{subscribe.EventRequestOut})
+	subscribeMulti = func([]string, []string, ...int) (*subscribe.Subscription, error) {
+		return subs, nil
+	}
+	defer func() { subscribeMulti = subscribe.SubscribeMulti }()
+
+	level1Client := &lostOutputsLevel1Client{UtxodbLevel1Client: testutil.NewUtxodbLevel1Client()}
+	trc := newFundedTestClient(t, level1Client)
+	target := address.Random()
+	level1Client.OnPost = func(tx *valuetransaction.Transaction) {
+		level1Client.lost = &target
+		subs.HostMessages <- &subscribe.HostMessage{
+			Sender:  hosts[0],
+			Message: append(subscribe.RequestOutPattern(trc.ChainID.String(), tx.ID().String(), 0), "1", "0", "1"),
+		}
+	}
+
+	tx, err := trc.MintAndRegister(MintAndRegisterParams{
+		Supply:            5,
+		MintTarget:        target,
+		WaitForCompletion: true,
+		PublisherHosts:    hosts,
+		Confirmation:      ConfirmSubscribe,
+		Timeout:           time.Second,
+		VerifySupply:      true,
+	})
+	require.True(t, errors.Is(err, ErrSupplyMismatch))
+	require.NotNil(t, tx)
+	confirmed, err := level1Client.IsConfirmed(tx.ID())
+	require.NoError(t, err)
+	require.True(t, confirmed)
+}
+
+func TestMintAndRegisterMalformedPublisherHost(t *testing.T) {
+	trc := newTestClient(0)
+	_, err := trc.MintAndRegister(MintAndRegisterParams{
+		Supply:            1,
+		WaitForCompletion: true,
+		PublisherHosts:    []string{"wasp1:5550", "wasp2"},
+	})
+	require.Error(t, err)
+
+	// 2 hosts after removing the duplicate
+	_, err = trc.MintAndRegister(MintAndRegisterParams{
+		Supply:            1,
+		WaitForCompletion: true,
+		PublisherHosts:    []string{"wasp1:5550", "wasp1:5550", "wasp2:5550"},
+		PublisherQuorum:   3,
+	})
+	require.Error(t, err)
+}
+
+func TestMintAndRegisterDefaultTarget(t *testing.T) {
+	trc, level1Client := newUtxodbTestClient(t)
+	ownerAddr := trc.OwnerAddress()
+
+	tx, err := trc.MintAndRegister(MintAndRegisterParams{Supply: 10})
+	require.NoError(t, err)
+
+	outs, err := level1Client.GetConfirmedAccountOutputs(&ownerAddr)
+	require.NoError(t, err)
+	bals, _ := txutil.OutputBalancesByColor(outs)
+	require.EqualValues(t, 10, bals[(balance.Color)(tx.ID())])
+}
+
+func TestMintAndRegisterMultipleTargets(t *testing.T) {
+	trc, level1Client := newUtxodbTestClient(t)
+	ownerAddr := trc.OwnerAddress()
+
+	targets := map[address.Address]int64{
+		address.Random(): 3,
+		address.Random(): 7,
+	}
+	_, err := trc.MintAndRegister(MintAndRegisterParams{Supply: 11, MintTargets: targets})
+	require.Error(t, err)
+	_, err = trc.MintAndRegister(MintAndRegisterParams{Supply: 10, MintTargets: targets, MintTarget: ownerAddr})
+	require.Error(t, err)
+
+	tx, err := trc.MintAndRegister(MintAndRegisterParams{Supply: 10, MintTargets: targets})
+	require.NoError(t, err)
+	require.NoError(t, trc.verifySupply(tx, MintAndRegisterParams{Supply: 10, MintTargets: targets}))
+
+	for addr, amount := range targets {
+		addr := addr
+		outs, err := level1Client.GetConfirmedAccountOutputs(&addr)
+		require.NoError(t, err)
+		bals, _ := txutil.OutputBalancesByColor(outs)
+		require.EqualValues(t, amount, bals[(balance.Color)(tx.ID())])
+	}
+}
+
+func TestMintAndRegisterFee(t *testing.T) {
+	trc, level1Client := newUtxodbTestClient(t)
+
+	_, err := trc.BuildUnsigned(MintAndRegisterParams{Supply: 10, Fee: &Fee{Color: balance.Color{1}, Amount: 5}})
+	require.Error(t, err)
+	_, err = trc.BuildUnsigned(MintAndRegisterParams{Supply: 10, Fee: &Fee{Color: balance.ColorIOTA, Amount: 0}})
+	require.Error(t, err)
+
+	tx, err := trc.BuildUnsigned(MintAndRegisterParams{Supply: 10, Fee: &Fee{Color: balance.ColorIOTA, Amount: 5}})
+	require.NoError(t, err)
+	require.EqualValues(t, 5, tx.Requests()[0].Transfer().Balance(balance.ColorIOTA))
+
+	// the fee is accepted by the ledger
+	tx, err = trc.MintAndRegister(MintAndRegisterParams{Supply: 10, Fee: &Fee{Color: balance.ColorIOTA, Amount: 5}})
+	require.NoError(t, err)
+	require.EqualValues(t, 5, tx.Requests()[0].Transfer().Balance(balance.ColorIOTA))
+	confirmed, err := level1Client.IsConfirmed(tx.ID())
+	require.NoError(t, err)
+	require.True(t, confirmed)
+}
+
+func TestMakeMintArgsDeadline(t *testing.T) {
+	_, err := makeMintArgs(MintAndRegisterParams{Deadline: time.Now().Add(-time.Second)})
+	require.Error(t, err)
+
+	deadline := time.Now().Add(time.Minute)
+	args, err := makeMintArgs(MintAndRegisterParams{Deadline: deadline})
+	require.NoError(t, err)
+	dl, ok, err := codec.DecodeInt64(args.MustGet(tokenregistry.VarReqDeadline))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.EqualValues(t, deadline.UnixNano(), dl)
+}
+
+func TestFetchStatusError(t *testing.T) {
+	err := &FetchStatusError{Errors: map[string]error{
+		StatusSourceRegistry: fmt.Errorf("registry failed"),
+		StatusSourceBalance:  fmt.Errorf("balance failed"),
+	}}
+	require.True(t, err.Failed(StatusSourceBalance))
+	require.False(t, err.Failed(StatusSourceState))
+	require.EqualValues(t, "FetchStatus failed: balance: balance failed; registry: registry failed", err.Error())
+}
+
+func TestClose(t *testing.T) {
+	trc := newTestClient(0)
+	subs := subscribe.NewSubscription([]string{"host1"}, []string{subscribe.EventRequestOut})
+	require.NoError(t, trc.addSubscription(subs))
+
+	require.NoError(t, trc.Close())
+	start := time.Now()
+	require.False(t, subs.WaitForPattern([]string{subscribe.EventRequestOut}, 10*time.Second))
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+	require.NoError(t, trc.Close())
+
+	// closing the subscription again, as MintAndRegister does, must not panic
+	trc.closeSubscription(subs)
+	require.Equal(t, ErrClientClosed, trc.addSubscription(subscribe.NewSubscription([]string{"host1"}, nil)))
+}
+
+func TestMintAndRegisterPending(t *testing.T) {
+	trc, _ := newUtxodbTestClient(t)
+
+	start := time.Now()
+	pending := &PendingMint{}
+	tx, err := trc.MintAndRegister(MintAndRegisterParams{Supply: 10, Pending: pending})
+	require.NoError(t, err)
+	require.EqualValues(t, tx.ID(), pending.TxID)
+	require.EqualValues(t, (balance.Color)(tx.ID()), pending.Color)
+	require.False(t, pending.SubmittedAt.Before(start))
+	require.EqualValues(t, coretypes.NewRequestID(tx.ID(), 0), pending.RequestID())
+}
+
+func TestMintAndRegisterIdempotencyKey(t *testing.T) {
+	trc, _ := newUtxodbTestClient(t)
+
+	tx, err := trc.MintAndRegister(MintAndRegisterParams{Supply: 10, IdempotencyKey: "mint1"})
+	require.NoError(t, err)
+
+	dup, err := trc.MintAndRegister(MintAndRegisterParams{Supply: 10, IdempotencyKey: "mint1"})
+	require.Equal(t, ErrDuplicateMint, err)
+	require.EqualValues(t, tx.ID(), dup.ID())
+
+	_, err = trc.MintAndRegister(MintAndRegisterParams{Supply: 10, IdempotencyKey: "mint2"})
+	require.NoError(t, err)
+
+	trc.IdempotencyWindow = time.Nanosecond
+	_, err = trc.MintAndRegister(MintAndRegisterParams{Supply: 10, IdempotencyKey: "mint1"})
+	require.NoError(t, err)
+}
+
+// failingPostLevel1Client is a UtxodbLevel1Client which rejects the posted transactions while fail is set
+type failingPostLevel1Client struct {
+	*testutil.UtxodbLevel1Client
+	fail bool
+}
+
+var errPostRejected = errors.New("transaction rejected")
+
+func (c *failingPostLevel1Client) PostTransaction(tx *valuetransaction.Transaction) error {
+	if c.fail {
+		return errPostRejected
+	}
+	return c.UtxodbLevel1Client.PostTransaction(tx)
+}
+
+func (c *failingPostLevel1Client) PostAndWaitForConfirmation(tx *valuetransaction.Transaction) error {
+	return c.PostTransaction(tx)
+}
+
+func TestMintAndRegisterIdempotencyKeyFailedPost(t *testing.T) {
+	level1Client := &failingPostLevel1Client{UtxodbLevel1Client: testutil.NewUtxodbLevel1Client()}
+	trc := newFundedTestClient(t, level1Client)
+
+	for _, wait := range []bool{false, true} {
+		key := fmt.Sprintf("mint-%v", wait)
+		par := MintAndRegisterParams{Supply: 10, IdempotencyKey: key, WaitForCompletion: wait, Confirmation: ConfirmPoll}
+		level1Client.fail = true
+		tx, err := trc.MintAndRegister(par)
+		require.True(t, errors.Is(err, errPostRejected))
+		require.Nil(t, tx)
+
+		// nothing was posted: the retry with the same key is not a duplicate
+		level1Client.fail = false
+		par.WaitForCompletion = false // no node to wait for
+		tx, err = trc.MintAndRegister(par)
+		require.NoError(t, err)
+		require.NotNil(t, tx)
+	}
+}
+
+func TestBuildUnsigned(t *testing.T) {
+	trc, _ := newUtxodbTestClient(t)
+
+	tx, err := trc.BuildUnsigned(MintAndRegisterParams{Supply: 10, Description: "unsigned"})
+	require.NoError(t, err)
+	require.False(t, tx.SignaturesValid())
+	require.Len(t, tx.Requests(), 1)
+
+	tx.Sign(trc.SigScheme)
+	require.True(t, tx.SignaturesValid())
+}
+
+func TestStatusFingerprint(t *testing.T) {
+	colors := []balance.Color{{1}, {2}, {3}, {4}}
+	makeStatus := func(order []int) *Status {
+		bal := make(map[balance.Color]int64)
+		reg := make(map[balance.Color]*tokenregistry.TokenMetadata)
+		for _, i := range order {
+			bal[colors[i]] = int64(i + 1)
+			reg[colors[i]] = &tokenregistry.TokenMetadata{Supply: int64(i + 1), Description: colors[i].String()}
+		}
+		return NewStatus(bal, reg)
+	}
+	s1 := makeStatus([]int{0, 1, 2, 3})
+	s2 := makeStatus([]int{3, 1, 0, 2})
+	s2.FetchedAt = time.Now()
+	require.Equal(t, s1.Fingerprint(), s2.Fingerprint())
+
+	s2.Balance[colors[0]]++
+	require.NotEqual(t, s1.Fingerprint(), s2.Fingerprint())
+	s2.Balance[colors[0]]--
+	s2.Registry[colors[3]].Description = "changed"
+	require.NotEqual(t, s1.Fingerprint(), s2.Fingerprint())
+}
+
+func TestStatusDiff(t *testing.T) {
+	colors := []balance.Color{{1}, {2}, {3}}
+	prev := NewStatus(
+		map[balance.Color]int64{colors[0]: 10, colors[1]: 5},
+		map[balance.Color]*tokenregistry.TokenMetadata{
+			colors[0]: {Supply: 10, Description: "first"},
+			colors[1]: {Supply: 5, Description: "second"},
+		},
+	)
+	s := NewStatus(
+		map[balance.Color]int64{colors[0]: 10, colors[1]: 8, colors[2]: 1},
+		map[balance.Color]*tokenregistry.TokenMetadata{
+			colors[0]: {Supply: 10, Description: "first"},
+			colors[2]: {Supply: 1, Description: "third"},
+		},
+	)
+	s.FetchedAt = time.Now()
+
+	diff := s.Diff(prev)
+	require.Equal(t, []balance.Color{colors[2]}, diff.Added)
+	require.Equal(t, []balance.Color{colors[1]}, diff.Removed)
+	require.Empty(t, diff.Changed)
+	require.Equal(t, map[balance.Color]int64{colors[1]: 3, colors[2]: 1}, diff.BalanceDeltas)
+
+	s2 := NewStatus(
+		map[balance.Color]int64{colors[0]: 10},
+		map[balance.Color]*tokenregistry.TokenMetadata{
+			colors[0]: {Supply: 10, Description: "changed"},
+			colors[2]: {Supply: 1, Description: "third"},
+		},
+	)
+	diff = s2.Diff(s)
+	require.Empty(t, diff.Added)
+	require.Empty(t, diff.Removed)
+	require.Equal(t, []balance.Color{colors[0]}, diff.Changed)
+	require.Equal(t, map[balance.Color]int64{colors[1]: -8, colors[2]: -1}, diff.BalanceDeltas)
+
+	diff = s.Diff(s)
+	require.True(t, diff.IsEmpty())
+	require.Len(t, s.Diff(nil).Added, 2)
+}
+
+func TestFilterByDescription(t *testing.T) {
+	registry := map[balance.Color]*tokenregistry.TokenMetadata{
+		{1}: {Supply: 1, Description: "Gold Coin"},
+		{2}: {Supply: 2, Description: "silver coin"},
+		{3}: {Supply: 3, Description: "Ticket"},
+	}
+	found := filterByDescription(registry, "COIN")
+	require.Len(t, found, 2)
+	require.Equal(t, registry[balance.Color{1}], found[balance.Color{1}])
+	require.Equal(t, registry[balance.Color{2}], found[balance.Color{2}])
+
+	require.Empty(t, filterByDescription(registry, "bronze"))
+}
+
+func TestRegistryChanges(t *testing.T) {
+	prev := map[balance.Color]*tokenregistry.TokenMetadata{
+		{1}: {Supply: 1, Description: "first"},
+		{2}: {Supply: 2, Description: "second"},
+		{3}: {Supply: 3, Description: "third"},
+	}
+	registry := map[balance.Color]*tokenregistry.TokenMetadata{
+		{1}: {Supply: 1, Description: "first"},
+		{3}: {Supply: 3, Description: "changed"},
+		{4}: {Supply: 4, Description: "fourth"},
+	}
+	require.Equal(t, []RegistryChange{
+		{StateIndex: 7, Color: balance.Color{2}},
+		{StateIndex: 7, Color: balance.Color{3}, Metadata: registry[balance.Color{3}]},
+		{StateIndex: 7, Color: balance.Color{4}, Metadata: registry[balance.Color{4}]},
+	}, registryChanges(7, prev, registry))
+
+	require.Empty(t, registryChanges(8, registry, registry))
+}
+
+func TestRegistrySyncCatchUp(t *testing.T) {
+	states := map[uint32]map[balance.Color]*tokenregistry.TokenMetadata{
+		1: {{1}: {Supply: 1, Description: "first"}},
+		2: {{1}: {Supply: 1, Description: "first"}, {2}: {Supply: 2, Description: "second"}},
+		3: {{2}: {Supply: 2, Description: "changed"}},
+	}
+	queried := make(map[uint32]int)
+	e := echo.New()
+	e.GET(routes.StateQuery(":chainID"), func(c echo.Context) error {
+		var req statequery.Request
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		idx := *req.StateIndex
+		queried[idx]++
+		vars := buffered.NewBufferedKVStore(mapdb.NewMapDB())
+		registry := collections.NewMap(vars, tokenregistry.VarStateTheRegistry)
+		for col, tm := range states[idx] {
+			col := col
+			registry.MustSetAt(col[:], encodeMetadata(t, tm))
+		}
+		results, err := req.Execute(vars)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, &statequery.Results{
+			KeyQueryResults: results,
+			StateIndex:      idx,
+			StateTxId:       model.NewValueTxID(&valuetransaction.ID{}),
+		})
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	trc := NewClient(chainclient.New(nil, client.NewWaspClient(srv.URL), coretypes.NewRandomChainID(), signaturescheme.RandBLS()), coretypes.Hn("tokenregistry"))
+	registry, err := trc.fetchRegistryAt(1)
+	require.NoError(t, err)
+
+	ch := make(chan RegistryChange, 10)
+	s := &registrySync{trc: trc, ctx: context.Background(), ch: ch, index: 1, registry: registry}
+	s.catchUp(3)
+	close(ch)
+
+	// state #2 is skipped: its changes are diffed together with the ones of state #3
+	require.Equal(t, map[uint32]int{1: 1, 3: 1}, queried)
+	var changes []RegistryChange
+	for change := range ch {
+		changes = append(changes, change)
+	}
+	require.Equal(t, []RegistryChange{
+		{StateIndex: 3, Color: balance.Color{1}},
+		{StateIndex: 3, Color: balance.Color{2}, Metadata: states[3][balance.Color{2}]},
+	}, changes)
+	require.EqualValues(t, 3, s.index)
+	require.Equal(t, states[3], s.registry)
+}
+
+func TestIsMintConfirmed(t *testing.T) {
+	trc, level1Client := newUtxodbTestClient(t)
+
+	tx, err := trc.BuildUnsigned(MintAndRegisterParams{Supply: 1})
+	require.NoError(t, err)
+	tx.Sign(trc.SigScheme)
+	confirmed, err := trc.IsMintConfirmed(tx)
+	require.NoError(t, err)
+	require.False(t, confirmed)
+
+	require.NoError(t, level1Client.PostTransaction(tx.Transaction))
+	confirmed, err = trc.IsMintConfirmed(tx)
+	require.NoError(t, err)
+	require.True(t, confirmed)
+
+	tx, err = trc.MintAndRegister(MintAndRegisterParams{Supply: 1})
+	require.NoError(t, err)
+	confirmed, err = trc.IsMintConfirmed(tx)
+	require.NoError(t, err)
+	require.True(t, confirmed)
+}
+
+func TestReadRegistrySnapshot(t *testing.T) {
+	tm := &tokenregistry.TokenMetadata{Supply: 10, Description: "first"}
+	var buf bytes.Buffer
+	buf.WriteByte(registrySnapshotVersion)
+	require.NoError(t, util.WriteUint32(&buf, 1))
+	buf.Write(balance.Color{1}.Bytes())
+	buf.Write(encodeMetadata(t, tm))
+
+	entries, err := readRegistrySnapshot(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.EqualValues(t, balance.Color{1}, entries[0].Color)
+	require.EqualValues(t, *tm, entries[0].TokenMetadata)
+
+	// a corrupted count fails on the end of the input
+	corrupted := buf.Bytes()
+	corrupted[1], corrupted[2], corrupted[3], corrupted[4] = 0xff, 0xff, 0xff, 0xff
+	_, err = readRegistrySnapshot(bytes.NewReader(corrupted))
+	require.Error(t, err)
+}
+
+// newStateServer returns a node which serves the state queries from vars, as the latest state with the given index
+func newStateServer(t *testing.T, vars buffered.BufferedKVStore, stateIndex uint32) *httptest.Server {
+	e := echo.New()
+	e.GET(routes.StateQuery(":chainID"), func(c echo.Context) error {
+		var req statequery.Request
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		results, err := req.Execute(vars)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, &statequery.Results{
+			KeyQueryResults: results,
+			StateIndex:      stateIndex,
+			StateTxId:       model.NewValueTxID(&valuetransaction.ID{}),
+		})
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newSignedStateServer returns a node which restricts the state readers to reader: it serves only
+// the state queries signed by it, from vars
+func newSignedStateServer(t *testing.T, chainID coretypes.ChainID, reader coretypes.AgentID, vars buffered.BufferedKVStore) *httptest.Server {
+	e := echo.New()
+	e.GET(routes.StateQuery(":chainID"), func(c echo.Context) error {
+		return c.JSON(http.StatusForbidden, model.NewHTTPError(http.StatusForbidden, "use the signed state query"))
+	})
+	e.POST(routes.StateQuerySigned(":chainID"), func(c echo.Context) error {
+		var signed statequery.SignedRequest
+		if err := c.Bind(&signed); err != nil {
+			return err
+		}
+		signer, req, err := signed.Verify(&chainID, time.Minute)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, model.NewHTTPError(http.StatusUnauthorized, err.Error()))
+		}
+		if signer != reader {
+			return c.JSON(http.StatusForbidden, model.NewHTTPError(http.StatusForbidden, "not a state reader"))
+		}
+		results, err := req.Execute(vars)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, &statequery.Results{
+			KeyQueryResults: results,
+			StateTxId:       model.NewValueTxID(&valuetransaction.ID{}),
+		})
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSignedStateQueries(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	reader := signaturescheme.RandBLS()
+	vars := buffered.NewBufferedKVStore(mapdb.NewMapDB())
+	registry := collections.NewMap(vars, tokenregistry.VarStateTheRegistry)
+	registry.MustSetAt(DefaultKeyFunc(balance.Color{1}), encodeMetadata(t, &tokenregistry.TokenMetadata{Supply: 10}))
+	srv := newSignedStateServer(t, chainID, coretypes.NewAgentIDFromAddress(reader.Address()), vars)
+	trc := NewClient(chainclient.New(testutil.NewUtxodbLevel1Client(), client.NewWaspClient(srv.URL), chainID, signaturescheme.RandBLS()), coretypes.Hn("tokenregistry"))
+
+	_, err := trc.fetchRegistry()
+	require.Error(t, err)
+
+	trc.ReaderSigScheme = reader
+	reg, err := trc.fetchRegistry()
+	require.NoError(t, err)
+	require.Len(t, reg, 1)
+	require.EqualValues(t, 10, reg[balance.Color{1}].Supply)
+
+	// the owner of the client is not a reader
+	trc.ReaderSigScheme = trc.SigScheme
+	_, err = trc.fetchRegistry()
+	require.Error(t, err)
+}
+
+func TestFetchStatusWholeRegistry(t *testing.T) {
+	vars := buffered.NewBufferedKVStore(mapdb.NewMapDB())
+	registry := collections.NewMap(vars, tokenregistry.VarStateTheRegistry)
+	n := registryPageSize*2 + 5
+	for i := 0; i < n; i++ {
+		col := balance.Color{byte(i), byte(i >> 8), 1}
+		registry.MustSetAt(DefaultKeyFunc(col), encodeMetadata(t, &tokenregistry.TokenMetadata{Supply: int64(i + 1)}))
+	}
+	srv := newStateServer(t, vars, 7)
+	trc := NewClient(chainclient.New(testutil.NewUtxodbLevel1Client(), client.NewWaspClient(srv.URL), coretypes.NewRandomChainID(), signaturescheme.RandBLS()), coretypes.Hn("tokenregistry"))
+
+	for _, partial := range []bool{false, true} {
+		status, err := trc.FetchStatusWithParams(FetchStatusParams{Partial: partial})
+		require.NoError(t, err)
+		require.Len(t, status.Registry, n)
+		require.EqualValues(t, 7, status.StateIndex)
+		require.NotNil(t, status.Balance)
+	}
+}
+
+func TestRegistrySyncResetWholeRegistry(t *testing.T) {
+	vars := buffered.NewBufferedKVStore(mapdb.NewMapDB())
+	registry := collections.NewMap(vars, tokenregistry.VarStateTheRegistry)
+	n := registryPageSize + 5
+	for i := 0; i < n; i++ {
+		col := balance.Color{byte(i), 2}
+		registry.MustSetAt(DefaultKeyFunc(col), encodeMetadata(t, &tokenregistry.TokenMetadata{Supply: int64(i + 1)}))
+	}
+	srv := newStateServer(t, vars, 5)
+	trc := NewClient(chainclient.New(testutil.NewUtxodbLevel1Client(), client.NewWaspClient(srv.URL), coretypes.NewRandomChainID(), signaturescheme.RandBLS()), coretypes.Hn("tokenregistry"))
+
+	ch := make(chan RegistryChange, 1)
+	s := &registrySync{trc: trc, ctx: context.Background(), ch: ch}
+	s.catchUp(5)
+	close(ch)
+
+	change := <-ch
+	require.NotNil(t, change.Reset)
+	require.EqualValues(t, 5, change.StateIndex)
+	require.Len(t, change.Reset.Registry, n)
+	require.NotNil(t, change.Reset.Balance)
+	require.Len(t, s.registry, n)
+}
+
+func TestFetchStatusFiltered(t *testing.T) {
+	registered := balance.Color{1}
+	unregistered := balance.Color{2}
+	missing := balance.Color{3}
+	vars := buffered.NewBufferedKVStore(mapdb.NewMapDB())
+	collections.NewMap(vars, tokenregistry.VarStateTheRegistry).MustSetAt(DefaultKeyFunc(registered),
+		encodeMetadata(t, &tokenregistry.TokenMetadata{Supply: 10, Description: "registered"}))
+	totalAssets := collections.NewMap(vars, string(accounts.TotalAssetsStateKey()))
+	totalAssets.MustSetAt(registered[:], util.Uint64To8Bytes(10))
+	totalAssets.MustSetAt(unregistered[:], util.Uint64To8Bytes(3))
+	srv := newStateServer(t, vars, 4)
+	trc := NewClient(chainclient.New(nil, client.NewWaspClient(srv.URL), coretypes.NewRandomChainID(), signaturescheme.RandBLS()), coretypes.Hn("tokenregistry"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	status, err := trc.FetchStatusFilteredCtx(ctx, []balance.Color{missing, registered, unregistered})
+	require.NoError(t, err)
+	require.EqualValues(t, 4, status.StateIndex)
+	require.Equal(t, map[balance.Color]int64{registered: 10, unregistered: 3}, status.Balance)
+	require.Len(t, status.Registry, 1)
+	require.Equal(t, "registered", status.Registry[registered].Description)
+
+	status, err = trc.FetchStatusFiltered([]balance.Color{missing})
+	require.NoError(t, err)
+	require.Empty(t, status.Balance)
+	require.Empty(t, status.Registry)
+}
+
+// lazyConfirmLevel1Client is a UtxodbLevel1Client which confirms a posted transaction only when it is
+// waited for, so until then the confirmed outputs still include the ones it spends
+type lazyConfirmLevel1Client struct {
+	*testutil.UtxodbLevel1Client
+	mutex   sync.Mutex
+	pending []*valuetransaction.Transaction
+}
+
+func (c *lazyConfirmLevel1Client) PostTransaction(tx *valuetransaction.Transaction) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.pending = append(c.pending, tx)
+	return nil
+}
+
+func (c *lazyConfirmLevel1Client) WaitForConfirmation(txid valuetransaction.ID) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for i, tx := range c.pending {
+		if tx.ID() == txid {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			return c.UtxoDB.AddTransaction(tx)
+		}
+	}
+	return nil
+}
+
+// confirmAll confirms the transactions not waited for
+func (c *lazyConfirmLevel1Client) confirmAll() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, tx := range c.pending {
+		if err := c.UtxoDB.AddTransaction(tx); err != nil {
+			return err
+		}
+	}
+	c.pending = nil
+	return nil
+}
+
+func TestImportRegistryWaitsForConfirmation(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	vars := buffered.NewBufferedKVStore(mapdb.NewMapDB())
+	registry := collections.NewMap(vars, tokenregistry.VarStateTheRegistry)
+	for i := 1; i <= 3; i++ {
+		tm := &tokenregistry.TokenMetadata{Supply: int64(i * 10), Description: fmt.Sprintf("token %d", i)}
+		registry.MustSetAt(DefaultKeyFunc(balance.Color{byte(i)}), encodeMetadata(t, tm))
+	}
+	source := NewClient(chainclient.New(testutil.NewUtxodbLevel1Client(), client.NewWaspClient(newStateServer(t, vars, 1).URL), chainID, signaturescheme.RandBLS()), coretypes.Hn("tokenregistry"))
+	var snapshot bytes.Buffer
+	require.NoError(t, source.ExportRegistry(&snapshot))
+
+	level1Client := &lazyConfirmLevel1Client{UtxodbLevel1Client: testutil.NewUtxodbLevel1Client()}
+	empty := buffered.NewBufferedKVStore(mapdb.NewMapDB())
+	target := NewClient(chainclient.New(level1Client, client.NewWaspClient(newStateServer(t, empty, 1).URL), chainID, signaturescheme.RandBLS()), coretypes.Hn("tokenregistry"))
+	ownerAddr := target.OwnerAddress()
+	require.NoError(t, level1Client.RequestFunds(&ownerAddr))
+
+	snapshotData := snapshot.Bytes()
+	colors, err := target.ImportRegistry(bytes.NewReader(snapshotData), MintAndRegisterParams{IdempotencyKey: "import"})
+	require.NoError(t, err)
+	require.Len(t, colors, 3)
+	// each entry has its own key: a retry of the import doesn't mint them again
+	retried, err := target.ImportRegistry(bytes.NewReader(snapshotData), MintAndRegisterParams{IdempotencyKey: "import"})
+	require.NoError(t, err)
+	require.EqualValues(t, colors, retried)
+	// the mints don't spend the same outputs
+	require.NoError(t, level1Client.confirmAll())
+	for _, col := range colors {
+		confirmed, err := level1Client.IsConfirmed((valuetransaction.ID)(col))
+		require.NoError(t, err)
+		require.True(t, confirmed)
+	}
+}
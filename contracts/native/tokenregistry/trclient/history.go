@@ -0,0 +1,95 @@
+package trclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/contracts/native/tokenregistry"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/model/statequery"
+)
+
+// ErrHistoryNotRetained is returned by TokenBalanceHistory when the node doesn't keep the past states
+// of the chain for the requested period
+var ErrHistoryNotRetained = errors.New("the node doesn't retain the history of the chain state")
+
+// BalancePoint is the supply of the token registered in the state with the given index.
+// Supply is 0 in states before the token was registered
+type BalancePoint struct {
+	StateIndex uint32
+	Timestamp  time.Time
+	Supply     int64
+}
+
+// TokenBalanceHistory returns the registered supply of the color in each state of the chain with the timestamp
+// between from and to, in ascending order. Past states are queried one by one with historical state queries,
+// starting from the latest one, so long periods result in many requests
+func (trc *TokenRegistryClient) TokenBalanceHistory(color balance.Color, from, to time.Time) ([]BalancePoint, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid period: %v is before %v", to, from)
+	}
+	query := trc.balancePointQuery(color)
+	res, err := trc.stateQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	var ret []BalancePoint
+	for {
+		point, err := balancePointFromResults(res)
+		if err != nil {
+			return nil, err
+		}
+		if point.Timestamp.Before(from) {
+			break
+		}
+		if !point.Timestamp.After(to) {
+			ret = append(ret, *point)
+		}
+		if point.StateIndex == 0 {
+			break
+		}
+		res, err = trc.stateQuery(trc.balancePointQuery(color).AtStateIndex(point.StateIndex - 1))
+		if model.IsHTTPNotFound(err) {
+			return nil, fmt.Errorf("%w: state #%d: %v", ErrHistoryNotRetained, point.StateIndex-1, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	// reverse to ascending order
+	for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+	return ret, nil
+}
+
+func (trc *TokenRegistryClient) balancePointQuery(color balance.Color) *statequery.Request {
+	query := statequery.NewRequest()
+	query.AddGeneralData()
+	query.AddMapElement(tokenregistry.VarStateTheRegistry, trc.keyFunc()(color))
+	return query
+}
+
+func balancePointFromResults(res *statequery.Results) (*BalancePoint, error) {
+	ret := &BalancePoint{
+		StateIndex: res.StateIndex,
+		Timestamp:  res.Timestamp.UTC(),
+	}
+	var value []byte
+	if elem := res.Get(tokenregistry.VarStateTheRegistry); elem != nil {
+		value = elem.MustMapElementResult()
+	}
+	if value == nil {
+		// not registered yet
+		return ret, nil
+	}
+	tm := &tokenregistry.TokenMetadata{}
+	if err := tm.Read(bytes.NewReader(value)); err != nil {
+		return nil, err
+	}
+	ret.Supply = tm.Supply
+	return ret, nil
+}
@@ -0,0 +1,108 @@
+package trclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/client"
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/contracts/native/tokenregistry"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/model/statequery"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// newHistoryServer serves the state queries on the given states, indexed by state index, one minute apart
+// from genesis. The states before retainedFrom are reported as not retained
+func newHistoryServer(t *testing.T, states []buffered.BufferedKVStore, genesis time.Time, retainedFrom uint32) *httptest.Server {
+	e := echo.New()
+	e.GET(routes.StateQuery(":chainID"), func(c echo.Context) error {
+		var req statequery.Request
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		index := uint32(len(states) - 1)
+		if req.StateIndex != nil {
+			index = *req.StateIndex
+		}
+		if index < retainedFrom || index >= uint32(len(states)) {
+			return c.JSON(http.StatusNotFound, model.NewHTTPError(http.StatusNotFound, "state not retained"))
+		}
+		results, err := req.Execute(states[index])
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, &statequery.Results{
+			KeyQueryResults: results,
+			StateIndex:      index,
+			Timestamp:       genesis.Add(time.Duration(index) * time.Minute),
+			StateTxId:       model.NewValueTxID(&valuetransaction.ID{}),
+		})
+	})
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTokenBalanceHistory(t *testing.T) {
+	color := balance.Color{7}
+	genesis := time.Unix(1600000000, 0).UTC()
+	// the token is registered in state #2, and its supply grows by 10 in each state
+	states := make([]buffered.BufferedKVStore, 6)
+	for i := range states {
+		states[i] = buffered.NewBufferedKVStore(mapdb.NewMapDB())
+		if i >= 2 {
+			registry := collections.NewMap(states[i], tokenregistry.VarStateTheRegistry)
+			registry.MustSetAt(DefaultKeyFunc(color), encodeMetadata(t, &tokenregistry.TokenMetadata{Supply: int64(i * 10)}))
+		}
+	}
+	at := func(index int) time.Time {
+		return genesis.Add(time.Duration(index) * time.Minute)
+	}
+	newClient := func(retainedFrom uint32) *TokenRegistryClient {
+		srv := newHistoryServer(t, states, genesis, retainedFrom)
+		return NewClient(chainclient.New(testutil.NewUtxodbLevel1Client(), client.NewWaspClient(srv.URL), coretypes.NewRandomChainID(), signaturescheme.RandBLS()), coretypes.Hn("tokenregistry"))
+	}
+
+	t.Run("period", func(t *testing.T) {
+		points, err := newClient(0).TokenBalanceHistory(color, at(1), at(4))
+		require.NoError(t, err)
+		require.EqualValues(t, []BalancePoint{
+			{StateIndex: 1, Timestamp: at(1), Supply: 0},
+			{StateIndex: 2, Timestamp: at(2), Supply: 20},
+			{StateIndex: 3, Timestamp: at(3), Supply: 30},
+			{StateIndex: 4, Timestamp: at(4), Supply: 40},
+		}, points)
+	})
+
+	t.Run("whole chain", func(t *testing.T) {
+		points, err := newClient(0).TokenBalanceHistory(color, genesis.Add(-time.Hour), at(10))
+		require.NoError(t, err)
+		require.Len(t, points, len(states))
+		require.EqualValues(t, 0, points[0].StateIndex)
+		require.EqualValues(t, 50, points[len(points)-1].Supply)
+	})
+
+	t.Run("not retained", func(t *testing.T) {
+		_, err := newClient(3).TokenBalanceHistory(color, at(1), at(4))
+		require.True(t, errors.Is(err, ErrHistoryNotRetained))
+	})
+
+	t.Run("invalid period", func(t *testing.T) {
+		_, err := newClient(0).TokenBalanceHistory(color, at(4), at(1))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,68 @@
+package trclient
+
+import (
+	"errors"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/sctransaction"
+)
+
+// DefaultIdempotencyWindow is the default IdempotencyWindow of TokenRegistryClient
+const DefaultIdempotencyWindow = 10 * time.Minute
+
+// ErrDuplicateMint is returned by MintAndRegister when a mint with the same IdempotencyKey was submitted
+// by the client within the IdempotencyWindow. The transaction of the previous mint is returned along with it,
+// or nil if the previous mint is still being built.
+// The key is released if the call fails before the transaction is posted, or the post fails with a definite error.
+// Otherwise the mint is considered submitted even if the call fails afterwards: a lost response can't be told
+// from a failed post (see chainclient.ErrOutcomeUnknown), so the guard errs on the side of not minting twice
+var ErrDuplicateMint = errors.New("mint with the same idempotency key was already submitted")
+
+type idempotentMint struct {
+	tx      *sctransaction.Transaction // nil while building
+	started time.Time
+}
+
+func (trc *TokenRegistryClient) idempotencyWindow() time.Duration {
+	if trc.IdempotencyWindow <= 0 {
+		return DefaultIdempotencyWindow
+	}
+	return trc.IdempotencyWindow
+}
+
+// beginIdempotentMint records the start of the mint with the key, or returns ErrDuplicateMint
+func (trc *TokenRegistryClient) beginIdempotentMint(key string) (*sctransaction.Transaction, error) {
+	trc.mutex.Lock()
+	defer trc.mutex.Unlock()
+
+	window := trc.idempotencyWindow()
+	for k, m := range trc.mints {
+		if time.Since(m.started) > window {
+			delete(trc.mints, k)
+		}
+	}
+	if m, ok := trc.mints[key]; ok {
+		return m.tx, ErrDuplicateMint
+	}
+	if trc.mints == nil {
+		trc.mints = make(map[string]*idempotentMint)
+	}
+	trc.mints[key] = &idempotentMint{started: time.Now()}
+	return nil, nil
+}
+
+func (trc *TokenRegistryClient) setIdempotentMintTx(key string, tx *sctransaction.Transaction) {
+	trc.mutex.Lock()
+	defer trc.mutex.Unlock()
+
+	if m, ok := trc.mints[key]; ok {
+		m.tx = tx
+	}
+}
+
+func (trc *TokenRegistryClient) abortIdempotentMint(key string) {
+	trc.mutex.Lock()
+	defer trc.mutex.Unlock()
+
+	delete(trc.mints, key)
+}
@@ -0,0 +1,281 @@
+package trclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/client"
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/contracts/native/tokenregistry"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/webapi/model/statequery"
+)
+
+// registrySnapshotVersion is the version of the binary format written by ExportRegistry
+const registrySnapshotVersion = byte(1)
+
+// registryPageSize is the number of registry entries fetched in one query
+const registryPageSize = 100
+
+// fetchRegistry loads the whole registry page by page
+func (trc *TokenRegistryClient) fetchRegistry() (map[balance.Color]*tokenregistry.TokenMetadata, error) {
+	ret, _, err := trc.fetchRegistryWith(context.Background(), nil)
+	return ret, err
+}
+
+// fetchRegistryWith is like fetchRegistry. If addQueries is not nil, it adds queries to the request
+// of the first page, and the results of that request are returned.
+// Each page is bounded by QueryTimeout, all of them by the context
+func (trc *TokenRegistryClient) fetchRegistryWith(ctx context.Context, addQueries func(query *statequery.Request)) (map[balance.Color]*tokenregistry.TokenMetadata, *statequery.Results, error) {
+	return trc.fetchRegistryPages(ctx, nil, addQueries)
+}
+
+// fetchRegistryState loads the whole registry like fetchRegistryWith, together with the status of the state
+// queried with the first page. Balance of the status is left nil.
+// If a later page fails, the status is returned with the error
+func (trc *TokenRegistryClient) fetchRegistryState(ctx context.Context) (*chainclient.SCStatus, map[balance.Color]*tokenregistry.TokenMetadata, error) {
+	registry, first, err := trc.fetchRegistryWith(ctx, func(query *statequery.Request) {
+		query.AddGeneralData()
+	})
+	if first == nil {
+		return nil, nil, err
+	}
+	return trc.SCStatusFromResults(first), registry, err
+}
+
+// fetchRegistryAt loads the whole registry in the past state with the given index
+func (trc *TokenRegistryClient) fetchRegistryAt(stateIndex uint32) (map[balance.Color]*tokenregistry.TokenMetadata, error) {
+	ret, _, err := trc.fetchRegistryPages(context.Background(), &stateIndex, nil)
+	return ret, err
+}
+
+// fetchRegistryPages loads the registry page by page, in the state with the given index or in the latest state if nil.
+// On error, the results of the first page are returned if they were fetched
+func (trc *TokenRegistryClient) fetchRegistryPages(ctx context.Context, stateIndex *uint32, addQueries func(query *statequery.Request)) (map[balance.Color]*tokenregistry.TokenMetadata, *statequery.Results, error) {
+	ret := make(map[balance.Color]*tokenregistry.TokenMetadata)
+	var first *statequery.Results
+	cursor := statequery.MapCursor{}
+	for {
+		query := statequery.NewRequest()
+		query.AddMapFrom(tokenregistry.VarStateTheRegistry, cursor, registryPageSize)
+		if stateIndex != nil {
+			query.AtStateIndex(*stateIndex)
+		}
+		if first == nil && addQueries != nil {
+			addQueries(query)
+		}
+		res, err := trc.stateQueryCtx(ctx, query)
+		if err != nil {
+			return nil, first, err
+		}
+		if first == nil {
+			first = res
+		}
+		result := res.Get(tokenregistry.VarStateTheRegistry).MustMapResult()
+		page, err := decodeRegistry(result, trc.keyFunc())
+		if err != nil {
+			return nil, first, err
+		}
+		for col, tm := range page {
+			if _, ok := ret[col]; ok {
+				return nil, first, fmt.Errorf("duplicate registry entry for color %s", col.String())
+			}
+			ret[col] = tm
+		}
+		if result.Next == nil {
+			return ret, first, nil
+		}
+		cursor = *result.Next
+	}
+}
+
+// FetchRegistryAndConfig fetches the whole registry together with an element of another map in the state
+// of the contract, e.g. a configuration value. The element is queried in the same request as the first page
+// of the registry. It is nil if the map has no such element
+func (trc *TokenRegistryClient) FetchRegistryAndConfig(configMap kv.Key, configKey []byte) (map[balance.Color]*tokenregistry.TokenMetadata, []byte, error) {
+	if configMap == tokenregistry.VarStateTheRegistry {
+		// the results of the queries would be keyed by the same state key
+		return nil, nil, fmt.Errorf("config map can't be the registry")
+	}
+	registry, first, err := trc.fetchRegistryWith(context.Background(), func(query *statequery.Request) {
+		query.AddMapElement(configMap, configKey)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return registry, first.Get(configMap).MustMapElementResult(), nil
+}
+
+// ExportRegistry writes all entries of the registry to w in a versioned binary format:
+// version byte, number of entries and then color and binary TokenMetadata of each entry,
+// sorted by color
+func (trc *TokenRegistryClient) ExportRegistry(w io.Writer) error {
+	registry, err := trc.fetchRegistry()
+	if err != nil {
+		return err
+	}
+	colors := make([]balance.Color, 0, len(registry))
+	for col := range registry {
+		colors = append(colors, col)
+	}
+	sortColors(colors)
+	if err := util.WriteByte(w, registrySnapshotVersion); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, uint32(len(colors))); err != nil {
+		return err
+	}
+	for _, col := range colors {
+		if _, err := w.Write(col[:]); err != nil {
+			return err
+		}
+		if err := registry[col].Write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func minUint32(a, b uint32) uint32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// readRegistrySnapshot reads the snapshot written by ExportRegistry, in the original order
+func readRegistrySnapshot(r io.Reader) ([]*TokenMetadataWithColor, error) {
+	version, err := util.ReadByte(r)
+	if err != nil {
+		return nil, err
+	}
+	if version != registrySnapshotVersion {
+		return nil, fmt.Errorf("unsupported registry snapshot version %d", version)
+	}
+	var n uint32
+	if err := util.ReadUint32(r, &n); err != nil {
+		return nil, err
+	}
+	// n is not trusted: the entries are appended as they are read, so a corrupted count fails
+	// on the end of the input instead of allocating memory for it
+	ret := make([]*TokenMetadataWithColor, 0, minUint32(n, registryPageSize))
+	for i := uint32(0); i < n; i++ {
+		e := &TokenMetadataWithColor{}
+		if err := util.ReadColor(r, &e.Color); err != nil {
+			return nil, fmt.Errorf("reading entry #%d of %d: %w", i, n, err)
+		}
+		if err := e.TokenMetadata.Read(r); err != nil {
+			return nil, fmt.Errorf("reading entry #%d of %d: %w", i, n, err)
+		}
+		ret = append(ret, e)
+	}
+	return ret, nil
+}
+
+// ImportRegistry replays the snapshot written by ExportRegistry: for each entry it mints the same supply
+// and registers it with the same description and user defined data.
+// The color of the supply can't be preserved, because the new color is the ID of the minting transaction.
+// Entries with colors already present in the target registry are skipped, e.g. when restoring the registry
+// the snapshot was taken from. The import is not idempotent: the imported entries get new colors,
+// so importing the same snapshot again mints and registers all of them again.
+// The optional parameters are used as a template for the MintAndRegister calls. The next mint takes its inputs
+// from the confirmed outputs of the owner, so unless the template waits for completion, each mint is waited for
+// to be confirmed by the ledger before the next one is built. The IdempotencyKey of the template is suffixed with
+// "/" and the color of each entry in the snapshot, so an interrupted import can be retried with the same key
+// within the IdempotencyWindow: the entries imported by the previous attempt are not minted again, and their
+// colors are returned as well.
+// Returns mapping of the colors in the snapshot to the new colors
+func (trc *TokenRegistryClient) ImportRegistry(r io.Reader, params ...MintAndRegisterParams) (map[balance.Color]balance.Color, error) {
+	par := MintAndRegisterParams{}
+	if len(params) > 0 {
+		par = params[0]
+	}
+	entries, err := readRegistrySnapshot(r)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := trc.fetchRegistry()
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[balance.Color]balance.Color)
+	var unconfirmed *sctransaction.Transaction
+	for _, e := range entries {
+		if _, ok := existing[e.Color]; ok {
+			continue
+		}
+		if unconfirmed != nil {
+			// otherwise the confirmed outputs still include the ones spent by the previous mint
+			if err := trc.Level1Client.WaitForConfirmation(unconfirmed.ID()); err != nil {
+				return ret, fmt.Errorf("importing color %s: waiting for the previous mint: %w", e.Color.String(), err)
+			}
+		}
+		p := par
+		p.Supply = e.Supply
+		p.Description = e.Description
+		p.UserDefinedData = e.UserDefined
+		p.MintTarget = importMintTarget(par.MintTarget, e, trc.OwnerAddress())
+		p.MintTargets = nil // the supply of each record is minted to one address
+		if par.IdempotencyKey != "" {
+			// the key of the template would make each mint after the first a duplicate
+			p.IdempotencyKey = par.IdempotencyKey + "/" + e.Color.String()
+		}
+		tx, err := trc.MintAndRegister(p)
+		if errors.Is(err, ErrDuplicateMint) && tx != nil {
+			// imported by the previous attempt
+			err = nil
+		}
+		if err != nil {
+			return ret, fmt.Errorf("importing color %s: %w", e.Color.String(), err)
+		}
+		ret[e.Color] = (balance.Color)(tx.ID())
+		if !p.WaitForCompletion {
+			unconfirmed = tx
+		}
+	}
+	return ret, nil
+}
+
+// importMintTarget is the explicit target, or the owner of the record if it is an address,
+// or else the owner of the client
+func importMintTarget(target address.Address, e *TokenMetadataWithColor, ownerAddress address.Address) address.Address {
+	if target != (address.Address{}) {
+		return target
+	}
+	if e.Owner.IsAddress() {
+		return e.Owner.MustAddress()
+	}
+	return ownerAddress
+}
+
+// StreamRegistry writes all entries of the registry to w as newline-delimited JSON while they are streamed
+// by the node (see WaspClient.StreamStateMap). Unlike ExportRegistry, the registry is never loaded into memory.
+// Use ReadRegistryStream to parse it. If the stream is cut, client.ErrIncompleteMapStream is returned,
+// and the backup written to w must not be used.
+// The stream can't be signed: nodes which restrict the state readers refuse it, use ExportRegistry with ReaderSigScheme
+func (trc *TokenRegistryClient) StreamRegistry(w io.Writer) error {
+	return trc.WaspClient.StreamStateMap(trc.ContractID(), tokenregistry.VarStateTheRegistry, w)
+}
+
+// ReadRegistryStream parses the stream written by StreamRegistry and calls f for each entry of the registry
+func (trc *TokenRegistryClient) ReadRegistryStream(r io.Reader, f func(e *TokenMetadataWithColor) error) error {
+	keyFunc := trc.keyFunc()
+	return client.ReadMapStream(r, func(key []byte, value []byte) error {
+		color, err := colorFromKey(keyFunc, key)
+		if err != nil {
+			return err
+		}
+		e := &TokenMetadataWithColor{Color: color}
+		if err := e.TokenMetadata.Read(bytes.NewReader(value)); err != nil {
+			return err
+		}
+		return f(e)
+	})
+}
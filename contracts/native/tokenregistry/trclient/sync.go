@@ -0,0 +1,180 @@
+package trclient
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/contracts/native/tokenregistry"
+	"github.com/iotaledger/wasp/packages/subscribe"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/model/statequery"
+)
+
+// RegistryChange is a change of one entry of the registry in the state with the given index, delivered by SyncRegistry
+type RegistryChange struct {
+	StateIndex uint32
+	Color      balance.Color
+	// Metadata is the new metadata of the entry, nil if the entry was removed
+	Metadata *tokenregistry.TokenMetadata
+	// Reset is not nil if the changes since the last delivered state can't be replayed, e.g. the node doesn't
+	// retain the past states. The mirror must be replaced by Reset.Registry. Color and Metadata are not set
+	Reset *Status
+}
+
+// SyncRegistry delivers the changes of the registry after the state with index from, e.g. to keep a mirror
+// of the registry in sync. The node can't tell which keys a state changed, so each catch-up downloads
+// the whole registry, page by page, in the newest state only and diffs it with the last delivered one.
+// The states in between are not replayed: all changes carry the index of the newest state, and an entry
+// changed and changed back in between is not delivered. The catch-ups are triggered by the 'state' events
+// of PublisherHosts, so the cost is one full download per event; missed events are caught up with by the next one.
+// If the newest state can't be fetched, a RegistryChange with the full status in Reset is delivered
+// and the sync continues from it.
+// The channel is closed when the context is done
+func (trc *TokenRegistryClient) SyncRegistry(ctx context.Context, from uint32) (<-chan RegistryChange, error) {
+	hosts, err := subscribe.NewHostSet(trc.PublisherHosts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("SyncRegistry: no publisher hosts")
+	}
+	// subscribe before replaying, so no state after the replayed ones is missed
+	subs, err := subscribeMulti(hosts, []string{subscribe.EventState})
+	if err != nil {
+		return nil, err
+	}
+	if err := trc.addSubscription(subs); err != nil {
+		return nil, err
+	}
+	registry, err := trc.fetchRegistryAt(from)
+	if err != nil && !model.IsHTTPNotFound(err) {
+		trc.closeSubscription(subs)
+		return nil, err
+	}
+
+	ch := make(chan RegistryChange)
+	go func() {
+		defer close(ch)
+		defer trc.closeSubscription(subs)
+
+		s := &registrySync{trc: trc, ctx: ctx, ch: ch, index: from, registry: registry}
+		if latest, err := trc.latestStateIndex(); err == nil {
+			s.catchUp(latest)
+		}
+		chainID := trc.ChainID.String()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-subs.HostMessages:
+				// 'state' <chain ID> <state index> ...
+				if len(msg.Message) < 3 || msg.Message[0] != subscribe.EventState || msg.Message[1] != chainID {
+					continue
+				}
+				index, err := strconv.Atoi(msg.Message[2])
+				if err != nil || index < 0 {
+					continue
+				}
+				s.catchUp(uint32(index))
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// latestStateIndex returns the index of the latest state of the chain
+func (trc *TokenRegistryClient) latestStateIndex() (uint32, error) {
+	query := statequery.NewRequest()
+	query.AddGeneralData()
+	res, err := trc.stateQuery(query)
+	if err != nil {
+		return 0, err
+	}
+	return res.StateIndex, nil
+}
+
+// registrySync is the state of SyncRegistry: the registry in the last delivered state.
+// registry is nil if it is not known and the full status must be fetched
+type registrySync struct {
+	trc      *TokenRegistryClient
+	ctx      context.Context
+	ch       chan<- RegistryChange
+	index    uint32
+	registry map[balance.Color]*tokenregistry.TokenMetadata
+}
+
+// catchUp delivers the changes from the last delivered state to the state with the given index,
+// fetching the registry once. Failed queries are retried on the next call
+func (s *registrySync) catchUp(to uint32) {
+	if s.registry == nil {
+		if !s.reset() {
+			return
+		}
+	}
+	if s.index >= to {
+		return
+	}
+	registry, err := s.trc.fetchRegistryAt(to)
+	if model.IsHTTPNotFound(err) {
+		// the state is not retained anymore, or not reached by the queried node yet
+		s.reset()
+		return
+	}
+	if err != nil {
+		return
+	}
+	for _, change := range registryChanges(to, s.registry, registry) {
+		if !s.deliver(change) {
+			return
+		}
+	}
+	s.index = to
+	s.registry = registry
+}
+
+// reset delivers the full status, with the whole registry fetched page by page, and continues from its state
+func (s *registrySync) reset() bool {
+	bal, err := s.trc.fetchBalance(s.ctx)
+	if err != nil {
+		return false
+	}
+	scStatus, registry, err := s.trc.fetchRegistryState(s.ctx)
+	if err != nil {
+		return false
+	}
+	scStatus.Balance = bal
+	status := &Status{SCStatus: scStatus, Registry: registry}
+	if !s.deliver(RegistryChange{StateIndex: status.StateIndex, Reset: status}) {
+		return false
+	}
+	s.index = status.StateIndex
+	s.registry = status.Registry
+	return true
+}
+
+func (s *registrySync) deliver(change RegistryChange) bool {
+	select {
+	case s.ch <- change:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// registryChanges returns the changes from prev to the registry in the state with the given index,
+// sorted by color: removed entries first, then added and changed ones
+func registryChanges(stateIndex uint32, prev, registry map[balance.Color]*tokenregistry.TokenMetadata) []RegistryChange {
+	diff := NewStatus(nil, registry).Diff(NewStatus(nil, prev))
+	ret := make([]RegistryChange, 0, len(diff.Removed)+len(diff.Added)+len(diff.Changed))
+	for _, col := range diff.Removed {
+		ret = append(ret, RegistryChange{StateIndex: stateIndex, Color: col})
+	}
+	changed := append(append([]balance.Color{}, diff.Added...), diff.Changed...)
+	sortColors(changed)
+	for _, col := range changed {
+		ret = append(ret, RegistryChange{StateIndex: stateIndex, Color: col, Metadata: registry[col]})
+	}
+	return ret
+}
@@ -0,0 +1,87 @@
+package tokenregistry
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Formats of the user defined data, recorded in its leading byte by EncodeUserData
+const (
+	UserDataRaw  = byte(0) // the data are raw bytes
+	UserDataJSON = byte(1)
+)
+
+// UserDataCodec serializes structured user defined data of the token metadata
+type UserDataCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+var userDataCodecs = map[byte]UserDataCodec{
+	UserDataJSON: jsonCodec{},
+}
+
+// RegisterUserDataCodec adds the codec of another format, e.g. CBOR. It is not safe to call concurrently
+// with encoding or decoding
+func RegisterUserDataCodec(format byte, codec UserDataCodec) {
+	if format == UserDataRaw {
+		panic("RegisterUserDataCodec: the raw format can't have a codec")
+	}
+	userDataCodecs[format] = codec
+}
+
+// EncodeUserData serializes v to JSON, with the format in the leading byte
+func EncodeUserData(v interface{}) ([]byte, error) {
+	return EncodeUserDataWithFormat(UserDataJSON, v)
+}
+
+// EncodeUserDataWithFormat serializes v with the codec of the format, with the format in the leading byte.
+// With UserDataRaw v must be []byte
+func EncodeUserDataWithFormat(format byte, v interface{}) ([]byte, error) {
+	if format == UserDataRaw {
+		data, ok := v.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("EncodeUserData: raw user data must be []byte, got %T", v)
+		}
+		return append([]byte{UserDataRaw}, data...), nil
+	}
+	codec, ok := userDataCodecs[format]
+	if !ok {
+		return nil, fmt.Errorf("EncodeUserData: unknown format %d", format)
+	}
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{format}, data...), nil
+}
+
+// DecodeUserData deserializes the data encoded by EncodeUserData into v. Raw data are decoded into *[]byte
+func DecodeUserData(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return fmt.Errorf("DecodeUserData: no data")
+	}
+	if data[0] == UserDataRaw {
+		p, ok := v.(*[]byte)
+		if !ok {
+			return fmt.Errorf("DecodeUserData: raw user data must be decoded into *[]byte, got %T", v)
+		}
+		*p = append([]byte(nil), data[1:]...)
+		return nil
+	}
+	codec, ok := userDataCodecs[data[0]]
+	if !ok {
+		return fmt.Errorf("DecodeUserData: unknown format %d", data[0])
+	}
+	return codec.Unmarshal(data[1:], v)
+}
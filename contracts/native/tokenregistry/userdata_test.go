@@ -0,0 +1,32 @@
+package tokenregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserData(t *testing.T) {
+	type appData struct {
+		URL   string
+		Price int
+	}
+	data, err := EncodeUserData(&appData{URL: "https://example.com", Price: 10})
+	require.NoError(t, err)
+	require.EqualValues(t, UserDataJSON, data[0])
+
+	var decoded appData
+	require.NoError(t, DecodeUserData(data, &decoded))
+	require.EqualValues(t, appData{URL: "https://example.com", Price: 10}, decoded)
+
+	data, err = EncodeUserDataWithFormat(UserDataRaw, []byte{1, 2, 3})
+	require.NoError(t, err)
+	var raw []byte
+	require.NoError(t, DecodeUserData(data, &raw))
+	require.EqualValues(t, []byte{1, 2, 3}, raw)
+	require.Error(t, DecodeUserData(data, &decoded))
+
+	_, err = EncodeUserDataWithFormat(UserDataRaw, "not bytes")
+	require.Error(t, err)
+	require.Error(t, DecodeUserData([]byte{42}, &decoded))
+}
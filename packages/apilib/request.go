@@ -28,12 +28,52 @@ type CreateRequestTransactionParams struct {
 	Level1Client         level1.Level1Client
 	SenderSigScheme      signaturescheme.SignatureScheme
 	RequestSectionParams []RequestSectionParams
-	Mint                 map[address.Address]int64 // free tokens to be minted from IOTA color
+	Mint                 map[address.Address]int64                 // free tokens to be minted from IOTA color
+	Sign                 func(tx *sctransaction.Transaction) error // if not nil, signs the transaction instead of SenderSigScheme
 	Post                 bool
 	WaitForConfirmation  bool
 }
 
 func CreateRequestTransaction(par CreateRequestTransactionParams) (*sctransaction.Transaction, error) {
+	tx, err := BuildRequestTransaction(par)
+	if err != nil {
+		return nil, err
+	}
+	if par.Sign != nil {
+		if err = par.Sign(tx); err != nil {
+			return nil, err
+		}
+	} else {
+		tx.Sign(par.SenderSigScheme)
+	}
+
+	// semantic check just in case
+	if _, err := tx.Properties(); err != nil {
+		return nil, err
+	}
+
+	if !par.Post {
+		return tx, nil
+	}
+
+	if !par.WaitForConfirmation {
+		if err = par.Level1Client.PostTransaction(tx.Transaction); err != nil {
+			return nil, err
+		}
+		return tx, nil
+	}
+
+	err = par.Level1Client.PostAndWaitForConfirmation(tx.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// BuildRequestTransaction builds the request transaction from the outputs of the sender, without signing it.
+// The transaction can't pass the semantic check before it is signed, so it is not checked.
+// Sign, Post and WaitForConfirmation are ignored
+func BuildRequestTransaction(par CreateRequestTransactionParams) (*sctransaction.Transaction, error) {
 	senderAddr := par.SenderSigScheme.Address()
 	allOuts, err := par.Level1Client.GetConfirmedAccountOutputs(&senderAddr)
 	if err != nil {
@@ -65,28 +105,6 @@ func CreateRequestTransaction(par CreateRequestTransactionParams) (*sctransactio
 	if err != nil {
 		return nil, err
 	}
-	tx.Sign(par.SenderSigScheme)
-
-	// semantic check just in case
-	if _, err := tx.Properties(); err != nil {
-		return nil, err
-	}
 	//fmt.Printf("$$$$ dumping builder for %s\n%s\n", tx.ID().String(), dump)
-
-	if !par.Post {
-		return tx, nil
-	}
-
-	if !par.WaitForConfirmation {
-		if err = par.Level1Client.PostTransaction(tx.Transaction); err != nil {
-			return nil, err
-		}
-		return tx, nil
-	}
-
-	err = par.Level1Client.PostAndWaitForConfirmation(tx.Transaction)
-	if err != nil {
-		return nil, err
-	}
 	return tx, nil
 }
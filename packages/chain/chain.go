@@ -4,6 +4,7 @@
 package chain
 
 import (
+	"errors"
 	"fmt"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
@@ -15,6 +16,7 @@ import (
 	"github.com/iotaledger/wasp/packages/tcrypto"
 	"github.com/iotaledger/wasp/packages/vm/processors"
 	"sync"
+	"time"
 )
 
 type Chain interface {
@@ -40,6 +42,7 @@ type Chain interface {
 	IsDismissed() bool
 	// requests
 	GetRequestProcessingStatus(*coretypes.RequestID) RequestProcessingStatus
+	GetBacklog() ([]BacklogRequest, error)
 	EventRequestProcessed() *events.Event
 	// chain processors
 	Processors() *processors.ProcessorCache
@@ -90,6 +93,19 @@ type Operator interface {
 	Close()
 	//
 	IsRequestInBacklog(*coretypes.RequestID) bool
+	Backlog() []BacklogRequest
+}
+
+// ErrNotCommitteeNode is returned by GetBacklog when the node is not in the committee of the chain,
+// so it doesn't know the backlog
+var ErrNotCommitteeNode = errors.New("the node is not in the committee of the chain")
+
+// BacklogRequest is a request received by the committee and not processed yet
+type BacklogRequest struct {
+	RequestID    coretypes.RequestID
+	Target       coretypes.ContractID
+	EntryPoint   coretypes.Hname
+	WhenReceived time.Time
 }
 
 type chainConstructor func(
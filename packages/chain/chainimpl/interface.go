@@ -308,6 +308,15 @@ func (c *chainObj) GetRequestProcessingStatus(reqID *coretypes.RequestID) chain.
 	return chain.RequestProcessingStatusCompleted
 }
 
+// GetBacklog returns the requests in the backlog of the committee, or chain.ErrNotCommitteeNode
+// if the node is not in the committee
+func (c *chainObj) GetBacklog() ([]chain.BacklogRequest, error) {
+	if c.IsDismissed() || !c.isCommitteeNode.Load() {
+		return nil, chain.ErrNotCommitteeNode
+	}
+	return c.operator.Backlog(), nil
+}
+
 func (c *chainObj) Processors() *processors.ProcessorCache {
 	return c.procset
 }
@@ -6,6 +6,7 @@ package consensus
 import (
 	"fmt"
 	"github.com/iotaledger/wasp/packages/vm"
+	"sort"
 	"time"
 
 	"github.com/iotaledger/wasp/packages/chain"
@@ -64,7 +65,7 @@ func (op *operator) requestFromMsg(reqMsg *chain.RequestMsg) (*request, bool) {
 		ret.reqTx = reqMsg.Transaction
 		ret.freeTokens = reqMsg.FreeTokens
 		op.requests[*reqId] = ret
-		op.addRequestIdConcurrent(reqId)
+		op.addRequestIdConcurrent(reqMsg)
 		newMsg = true
 	}
 	if newMsg {
@@ -187,11 +188,16 @@ func takeRefs(reqs []*request) []vm.RequestRefWithFreeTokens {
 	return ret
 }
 
-func (op *operator) addRequestIdConcurrent(reqId *coretypes.RequestID) {
+func (op *operator) addRequestIdConcurrent(reqMsg *chain.RequestMsg) {
 	op.concurrentAccessMutex.Lock()
 	defer op.concurrentAccessMutex.Unlock()
 
-	op.requestIdsProtected[*reqId] = true
+	op.requestIdsProtected[*reqMsg.RequestId()] = chain.BacklogRequest{
+		RequestID:    *reqMsg.RequestId(),
+		Target:       reqMsg.RequestBlock().Target(),
+		EntryPoint:   reqMsg.RequestBlock().EntryPointCode(),
+		WhenReceived: time.Now(),
+	}
 }
 
 func (op *operator) removeRequestIdConcurrent(reqId *coretypes.RequestID) {
@@ -212,3 +218,18 @@ func (op *operator) hasRequestIdConcurrent(reqId *coretypes.RequestID) bool {
 func (op *operator) IsRequestInBacklog(reqId *coretypes.RequestID) bool {
 	return op.hasRequestIdConcurrent(reqId)
 }
+
+// Backlog returns the requests in the backlog, in the order they were received
+func (op *operator) Backlog() []chain.BacklogRequest {
+	op.concurrentAccessMutex.RLock()
+	defer op.concurrentAccessMutex.RUnlock()
+
+	ret := make([]chain.BacklogRequest, 0, len(op.requestIdsProtected))
+	for _, req := range op.requestIdsProtected {
+		ret = append(ret, req)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].WhenReceived.Before(ret[j].WhenReceived)
+	})
+	return ret
+}
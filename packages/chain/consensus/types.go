@@ -57,7 +57,7 @@ type operator struct {
 
 	// data for concurrent access, from APIs mostly
 	concurrentAccessMutex sync.RWMutex
-	requestIdsProtected   map[coretypes.RequestID]bool
+	requestIdsProtected   map[coretypes.RequestID]chain.BacklogRequest
 
 	// Channels for accepting external events.
 	eventStateTransitionMsgCh           chan *chain.StateTransitionMsg
@@ -114,7 +114,7 @@ func NewOperator(committee chain.Chain, dkshare *tcrypto.DKShare, log *logger.Lo
 		chain:                               committee,
 		dkshare:                             dkshare,
 		requests:                            make(map[coretypes.RequestID]*request),
-		requestIdsProtected:                 make(map[coretypes.RequestID]bool),
+		requestIdsProtected:                 make(map[coretypes.RequestID]chain.BacklogRequest),
 		peerPermutation:                     util.NewPermutation16(committee.Size(), nil),
 		log:                                 log.Named("c"),
 		eventStateTransitionMsgCh:           make(chan *chain.StateTransitionMsg),
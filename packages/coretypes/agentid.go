@@ -5,12 +5,19 @@ package coretypes
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
 	"io"
+	"strings"
+	"sync"
+	"unicode"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
-	"github.com/mr-tron/base58"
 )
 
 // AgentIDLength is the size of AgentID in bytes
@@ -45,13 +52,39 @@ func NewAgentIDFromSigScheme(sigScheme signaturescheme.SignatureScheme) AgentID
 // NewAgentIDFromBytes makes an AgentID from binary representation
 func NewAgentIDFromBytes(data []byte) (ret AgentID, err error) {
 	if len(data) != AgentIDLength {
-		err = ErrWrongDataLength
+		err = wrongDataLength(AgentIDLength, len(data))
 		return
 	}
 	copy(ret[:], data)
 	return
 }
 
+// NewAddressAgentIDFromBytes is like NewAgentIDFromBytes, but also checks the agent ID represents an address,
+// i.e. its hname is zero, of a supported version
+func NewAddressAgentIDFromBytes(data []byte) (AgentID, error) {
+	ret, err := NewAgentIDFromBytes(data)
+	if err != nil {
+		return ret, err
+	}
+	if _, err := ret.Address(); err != nil {
+		return ret, err
+	}
+	return ret, nil
+}
+
+// NewContractAgentIDFromBytes is like NewAgentIDFromBytes, but also checks the agent ID represents
+// a smart contract, i.e. its hname is not zero
+func NewContractAgentIDFromBytes(data []byte) (AgentID, error) {
+	ret, err := NewAgentIDFromBytes(data)
+	if err != nil {
+		return ret, err
+	}
+	if ret.IsAddress() {
+		return ret, ErrWrongAgentKind
+	}
+	return ret, nil
+}
+
 // NewRandomAgentID creates random AgentID
 func NewRandomAgentID() AgentID {
 	chainID := NewRandomChainID()
@@ -59,6 +92,15 @@ func NewRandomAgentID() AgentID {
 	return NewAgentIDFromContractID(NewContractID(chainID, hname))
 }
 
+// Bytes returns a copy of the binary representation of the agent ID.
+// Unlike a[:], the returned slice doesn't alias the array, so changing it doesn't change the agent ID
+func (a AgentID) Bytes() []byte {
+	ret := make([]byte, AgentIDLength)
+	copy(ret, a[:])
+	return ret
+}
+
+// chainIDField and hnameField alias the array of the agent ID. They must not be exposed
 func (a *AgentID) chainIDField() []byte {
 	return a[:ChainIDLength]
 }
@@ -74,7 +116,39 @@ func (a AgentID) IsAddress() bool {
 	return bytes.Equal(a.hnameField(), z[:])
 }
 
-// MustAddress takes address or panic if not address
+// IsContractOn checks if the agentID represents a contract on the given chain
+func (a AgentID) IsContractOn(chainID ChainID) bool {
+	return !a.IsAddress() && bytes.Equal(a.chainIDField(), chainID[:])
+}
+
+// ChainID returns the chain ID field of the agent ID. For a contract it is the chain of the contract,
+// for an address it is the chain ID with the same bytes as the address (see NewAgentIDFromAddress)
+func (a AgentID) ChainID() (ret ChainID) {
+	copy(ret[:], a.chainIDField())
+	return
+}
+
+// supportedAddressVersions are the address versions of the ledger an agent ID can represent
+var supportedAddressVersions = map[byte]bool{
+	address.VersionED25519: true,
+	address.VersionBLS:     true,
+}
+
+// Address takes the address the agent ID represents. Unlike MustAddress, it returns an error if the agent ID
+// is not an address or if the address has a version not supported by the ledger, e.g. of a newer kind
+func (a AgentID) Address() (ret address.Address, err error) {
+	if !a.IsAddress() {
+		err = ErrWrongAgentKind
+		return
+	}
+	copy(ret[:], a.chainIDField())
+	if !supportedAddressVersions[ret[0]] {
+		err = fmt.Errorf("%w: %d", ErrAddressVersion, ret[0])
+	}
+	return
+}
+
+// MustAddress takes address or panic if not address. The address version is not checked
 func (a AgentID) MustAddress() (ret address.Address) {
 	if !a.IsAddress() {
 		panic("not an address")
@@ -128,6 +202,45 @@ func NewAgentIDFromString(s string) (ret AgentID, err error) {
 	return
 }
 
+// ScanAgentID parses the agent ID at the beginning of the string, ignoring leading whitespace.
+// The agent ID is either in the human-readable form (see String) or base58 encoded, and it ends
+// with whitespace or at the end of the string. The rest of the string after it is returned with
+// leading whitespace removed
+func ScanAgentID(s string) (ret AgentID, rest string, err error) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	token := s
+	if i := strings.IndexFunc(s, unicode.IsSpace); i >= 0 {
+		token, rest = s[:i], strings.TrimLeftFunc(s[i:], unicode.IsSpace)
+	}
+	if token == "" {
+		err = errors.New("ScanAgentID: no agent ID found")
+		return
+	}
+	if strings.HasPrefix(token, "A/") || strings.HasPrefix(token, "C/") {
+		ret, err = NewAgentIDFromString(token)
+	} else {
+		ret, err = DecodeAgentID(token, EncodingBase58)
+	}
+	if err != nil {
+		return AgentID{}, "", fmt.Errorf("ScanAgentID: invalid agent ID '%s': %v", token, err)
+	}
+	return ret, rest, nil
+}
+
+// NormalizeAgentIDString parses the agent ID in any of the forms accepted by ScanAgentID and returns
+// its canonical human-readable form (see String), e.g. to key caches by the string form.
+// Surrounding whitespace is ignored, but nothing else may follow the agent ID
+func NormalizeAgentIDString(s string) (string, error) {
+	a, rest, err := ScanAgentID(s)
+	if err != nil {
+		return "", err
+	}
+	if rest != "" {
+		return "", fmt.Errorf("NormalizeAgentIDString: unexpected '%s' after the agent ID", rest)
+	}
+	return a.String(), nil
+}
+
 // ReadAgentID decodes from binary representation
 func ReadAgentID(r io.Reader, agentID *AgentID) error {
 	n, err := r.Read(agentID[:])
@@ -141,5 +254,37 @@ func ReadAgentID(r io.Reader, agentID *AgentID) error {
 }
 
 func (a AgentID) Base58() string {
-	return base58.Encode(a[:])
+	return a.Encode(EncodingBase58)
+}
+
+// anonymizationSalt is the key of AgentID.Anonymized. By default it is random,
+// so the anonymized IDs can't be correlated across restarts of the process
+var (
+	anonymizationSalt = func() []byte {
+		ret := make([]byte, 32)
+		if _, err := rand.Read(ret); err != nil {
+			panic(err)
+		}
+		return ret
+	}()
+	anonymizationSaltMutex sync.RWMutex
+)
+
+// SetAnonymizationSalt sets the salt of AgentID.Anonymized, e.g. from the configuration,
+// to correlate log entries of several runs. It should be called at initialization, before any logging.
+// The salt must be kept secret: with the salt known, the hash of any candidate agent ID can be compared
+func SetAnonymizationSalt(salt []byte) {
+	anonymizationSaltMutex.Lock()
+	defer anonymizationSaltMutex.Unlock()
+	anonymizationSalt = append([]byte(nil), salt...)
+}
+
+// Anonymized returns a short HMAC of the agent ID keyed by the salt, to be logged instead of the agent ID itself.
+// Same agent IDs give the same string, but the agent ID can't be recovered from it
+func (a AgentID) Anonymized() string {
+	anonymizationSaltMutex.RLock()
+	mac := hmac.New(sha256.New, anonymizationSalt)
+	anonymizationSaltMutex.RUnlock()
+	mac.Write(a[:])
+	return "anon/" + hex.EncodeToString(mac.Sum(nil)[:8])
 }
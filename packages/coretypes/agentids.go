@@ -0,0 +1,40 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package coretypes
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// EncodeAgentIDs encodes the list of agent IDs as uint32 count followed by fixed-length AgentIDs
+func EncodeAgentIDs(agentIDs []AgentID) []byte {
+	var buf bytes.Buffer
+	_ = util.WriteUint32(&buf, uint32(len(agentIDs)))
+	for i := range agentIDs {
+		buf.Write(agentIDs[i][:])
+	}
+	return buf.Bytes()
+}
+
+// DecodeAgentIDs decodes the list of agent IDs encoded with EncodeAgentIDs
+func DecodeAgentIDs(data []byte) ([]AgentID, error) {
+	r := bytes.NewReader(data)
+	var count uint32
+	if err := util.ReadUint32(r, &count); err != nil {
+		return nil, err
+	}
+	if uint64(r.Len()) != uint64(count)*AgentIDLength {
+		return nil, fmt.Errorf("DecodeAgentIDs: expected %d agent IDs, got %d bytes", count, r.Len())
+	}
+	ret := make([]AgentID, count)
+	for i := range ret {
+		if err := ReadAgentID(r, &ret[i]); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
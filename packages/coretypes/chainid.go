@@ -30,7 +30,7 @@ func NewChainIDFromBase58(b58 string) (ret ChainID, err error) {
 		return
 	}
 	if len(b) != ChainIDLength {
-		err = ErrWrongDataLength
+		err = wrongDataLength(ChainIDLength, len(b))
 		return
 	}
 	copy(ret[:], b)
@@ -66,7 +66,7 @@ func (chid *ChainID) Read(r io.Reader) error {
 		return err
 	}
 	if n != ChainIDLength {
-		return ErrWrongDataLength
+		return wrongDataLength(ChainIDLength, n)
 	}
 	return nil
 }
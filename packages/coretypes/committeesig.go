@@ -0,0 +1,29 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package coretypes
+
+import (
+	"fmt"
+
+	"go.dedis.ch/kyber/v3/pairing/bn256"
+	"go.dedis.ch/kyber/v3/sign/bdn"
+)
+
+var committeeSuite = bn256.NewSuite()
+
+// VerifyCommitteeSignature checks the signature of the committee on the state hash.
+// The committee signs with the BLS threshold signature scheme over BN256, as in the DKG of the nodes:
+// pubKey is the shared public key of the committee (a point of G2, the key behind the chain address)
+// and sig is the BDN signature recovered from the signature shares (a point of G1).
+// A signature which doesn't match returns false. Malformed keys or signatures return an error
+func VerifyCommitteeSignature(pubKey, stateHash, sig []byte) (bool, error) {
+	pub := committeeSuite.G2().Point()
+	if err := pub.UnmarshalBinary(pubKey); err != nil {
+		return false, fmt.Errorf("VerifyCommitteeSignature: wrong public key: %v", err)
+	}
+	if err := committeeSuite.G1().Point().UnmarshalBinary(sig); err != nil {
+		return false, fmt.Errorf("VerifyCommitteeSignature: wrong signature: %v", err)
+	}
+	return bdn.Verify(committeeSuite, pub, stateHash, sig) == nil, nil
+}
@@ -0,0 +1,39 @@
+package coretypes
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3/sign/bdn"
+	"go.dedis.ch/kyber/v3/util/random"
+)
+
+func TestVerifyCommitteeSignature(t *testing.T) {
+	priv, pub := bdn.NewKeyPair(committeeSuite, random.New())
+	pubKey, err := pub.MarshalBinary()
+	require.NoError(t, err)
+	stateHash := hashing.HashStrings("state")
+	sig, err := bdn.Sign(committeeSuite, priv, stateHash[:])
+	require.NoError(t, err)
+
+	ok, err := VerifyCommitteeSignature(pubKey, stateHash[:], sig)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	otherHash := hashing.HashStrings("other state")
+	ok, err = VerifyCommitteeSignature(pubKey, otherHash[:], sig)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	otherSig, err := bdn.Sign(committeeSuite, priv, otherHash[:])
+	require.NoError(t, err)
+	ok, err = VerifyCommitteeSignature(pubKey, stateHash[:], otherSig)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = VerifyCommitteeSignature(pubKey, stateHash[:], sig[1:])
+	require.Error(t, err)
+	_, err = VerifyCommitteeSignature(pubKey[1:], stateHash[:], sig)
+	require.Error(t, err)
+}
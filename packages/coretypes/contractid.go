@@ -26,6 +26,11 @@ func NewContractID(chid ChainID, contractHn Hname) (ret ContractID) {
 	return
 }
 
+// NewContractIDFromParts creates new ContractID from chainID and the name of the contract
+func NewContractIDFromParts(chid ChainID, contractName string) ContractID {
+	return NewContractID(chid, Hn(contractName))
+}
+
 // NewContractIDFromBytes creates contract ID frm its binary representation
 func NewContractIDFromBytes(data []byte) (ret ContractID, err error) {
 	err = ret.Read(bytes.NewReader(data))
@@ -99,7 +104,7 @@ func (scid *ContractID) Read(r io.Reader) error {
 		return err
 	}
 	if n != ContractIDLength {
-		return ErrWrongDataLength
+		return wrongDataLength(ContractIDLength, n)
 	}
 	return nil
 }
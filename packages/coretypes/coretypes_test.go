@@ -4,6 +4,12 @@
 package coretypes
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/wasp/packages/hashing"
@@ -112,9 +118,274 @@ func TestHname(t *testing.T) {
 	require.EqualValues(t, hn1, hn1back)
 }
 
+func TestHnameStringRoundTrip(t *testing.T) {
+	for _, hn := range []Hname{0, 1, Hn("first"), EntryPointInit, Hname(^uint32(0))} {
+		s := hn.String()
+		require.Len(t, s, 8)
+		back, err := HnameFromString(s)
+		require.NoError(t, err)
+		require.EqualValues(t, hn, back)
+	}
+	require.Equal(t, "00000000", Hname(0).String())
+
+	for _, s := range []string{"", "0", "1", "0000001", "000000001", "0x000001", "ABCDEF01", "+0000001", "0000000g"} {
+		_, err := HnameFromString(s)
+		require.Error(t, err, s)
+	}
+}
+
 func TestHnameCollision(t *testing.T) {
 	hn1 := Hn("doNothing")
 	hn2 := Hn("incCounter")
 
 	require.NotEqualValues(t, hn1, hn2)
 }
+
+func TestHnameRegistry(t *testing.T) {
+	hn := Hn("someContract")
+	_, ok := ResolveHname(hn)
+	require.False(t, ok)
+	require.EqualValues(t, hn.String(), hn.Readable())
+
+	reg := NewHnameRegistry()
+	require.EqualValues(t, hn, reg.Register("first", "someContract"))
+	SetHnameRegistry(reg)
+	defer SetHnameRegistry(nil)
+
+	name, ok := ResolveHname(hn)
+	require.True(t, ok)
+	require.EqualValues(t, "someContract", name)
+	require.EqualValues(t, "someContract("+hn.String()+")", hn.Readable())
+
+	_, ok = ResolveHname(Hn("unknown"))
+	require.False(t, ok)
+}
+
+func TestContractIDFromParts(t *testing.T) {
+	chid := NewRandomChainID()
+	scid := NewContractIDFromParts(chid, "root")
+	require.EqualValues(t, NewContractID(chid, Hn("root")), scid)
+	require.EqualValues(t, chid, scid.ChainID())
+	require.EqualValues(t, "cebf5908", scid.Hname().String())
+}
+
+func TestEncodeAgentIDs(t *testing.T) {
+	data := EncodeAgentIDs(nil)
+	decoded, err := DecodeAgentIDs(data)
+	require.NoError(t, err)
+	require.Len(t, decoded, 0)
+
+	agentIDs := make([]AgentID, 1000)
+	for i := range agentIDs {
+		agentIDs[i] = NewRandomAgentID()
+	}
+	data = EncodeAgentIDs(agentIDs)
+	require.Len(t, data, 4+len(agentIDs)*AgentIDLength)
+	decoded, err = DecodeAgentIDs(data)
+	require.NoError(t, err)
+	require.EqualValues(t, agentIDs, decoded)
+
+	_, err = DecodeAgentIDs(data[:len(data)-1])
+	require.Error(t, err)
+}
+
+func TestAgentIDIsContractOn(t *testing.T) {
+	chainID := NewRandomChainID()
+	contract := NewAgentIDFromContractID(NewContractID(chainID, Hn("test")))
+	require.True(t, contract.IsContractOn(chainID))
+	require.False(t, contract.IsContractOn(NewRandomChainID()))
+
+	addr := NewAgentIDFromAddress(address.Address(chainID))
+	require.False(t, addr.IsContractOn(chainID))
+}
+
+func TestAgentIDEncoding(t *testing.T) {
+	a := NewRandomAgentID()
+	require.EqualValues(t, a.Base58(), a.Encode(EncodingBase58))
+	for _, enc := range []Encoding{EncodingBase58, EncodingBase64URL} {
+		back, err := DecodeAgentID(a.Encode(enc), enc)
+		require.NoError(t, err)
+		require.EqualValues(t, a, back)
+	}
+	_, err := DecodeAgentID(a.Encode(EncodingBase64URL)[1:], EncodingBase64URL)
+	require.Error(t, err)
+}
+
+func TestAgentIDBytes(t *testing.T) {
+	a := NewRandomAgentID()
+	orig := a
+	b := a.Bytes()
+	require.EqualValues(t, a[:], b)
+
+	b[0]++
+	require.EqualValues(t, orig, a)
+
+	a[1]++
+	require.NotEqual(t, a[1], b[1])
+}
+
+func TestAgentIDFromBytesKind(t *testing.T) {
+	contractAgent := NewRandomAgentID()
+	addrAgent := NewAgentIDFromAddress(address.RandomOfType(address.VersionBLS))
+
+	a, err := NewContractAgentIDFromBytes(contractAgent[:])
+	require.NoError(t, err)
+	require.EqualValues(t, contractAgent, a)
+	_, err = NewContractAgentIDFromBytes(addrAgent[:])
+	require.Equal(t, ErrWrongAgentKind, err)
+
+	a, err = NewAddressAgentIDFromBytes(addrAgent[:])
+	require.NoError(t, err)
+	require.EqualValues(t, addrAgent, a)
+	_, err = NewAddressAgentIDFromBytes(contractAgent[:])
+	require.Equal(t, ErrWrongAgentKind, err)
+
+	_, err = NewAddressAgentIDFromBytes(addrAgent[:10])
+	require.True(t, errors.Is(err, ErrWrongDataLength))
+	require.Contains(t, err.Error(), fmt.Sprintf("expected %d bytes, got 10", AgentIDLength))
+
+	_, err = NewChainIDFromBytes(addrAgent[:20])
+	require.True(t, errors.Is(err, ErrWrongDataLength))
+	require.Contains(t, err.Error(), fmt.Sprintf("expected %d bytes, got 20", ChainIDLength))
+
+	_, err = NewContractIDFromBytes(addrAgent[:30])
+	require.True(t, errors.Is(err, ErrWrongDataLength))
+	require.Contains(t, err.Error(), fmt.Sprintf("expected %d bytes, got 30", ContractIDLength))
+
+	unknownVersion := NewAgentIDFromAddress(address.RandomOfType(7))
+	_, err = NewAddressAgentIDFromBytes(unknownVersion[:])
+	require.True(t, errors.Is(err, ErrAddressVersion))
+}
+
+func TestAgentIDAddress(t *testing.T) {
+	addr := address.RandomOfType(address.VersionED25519)
+	a, err := NewAgentIDFromAddress(addr).Address()
+	require.NoError(t, err)
+	require.EqualValues(t, addr, a)
+
+	_, err = NewAgentIDFromAddress(address.RandomOfType(7)).Address()
+	require.True(t, errors.Is(err, ErrAddressVersion))
+
+	_, err = NewRandomAgentID().Address()
+	require.Equal(t, ErrWrongAgentKind, err)
+}
+
+func TestAgentIDChainID(t *testing.T) {
+	chainID := NewRandomChainID()
+	require.EqualValues(t, chainID, NewAgentIDFromContractID(NewContractID(chainID, Hn("test"))).ChainID())
+
+	addr := address.RandomOfType(address.VersionED25519)
+	require.EqualValues(t, ChainID(addr), NewAgentIDFromAddress(addr).ChainID())
+}
+
+func TestAgentIDAnonymized(t *testing.T) {
+	a1 := NewRandomAgentID()
+	a2 := NewAgentIDFromAddress(address.RandomOfType(address.VersionED25519))
+	anon := a1.Anonymized()
+	require.Equal(t, anon, a1.Anonymized())
+	require.NotEqual(t, anon, a2.Anonymized())
+	require.NotContains(t, anon, a1.Base58())
+
+	oldSalt := anonymizationSalt
+	t.Cleanup(func() { SetAnonymizationSalt(oldSalt) })
+	SetAnonymizationSalt([]byte("salt"))
+	require.NotEqual(t, anon, a1.Anonymized())
+	anon = a1.Anonymized()
+	SetAnonymizationSalt([]byte("salt"))
+	require.Equal(t, anon, a1.Anonymized())
+
+	mac := hmac.New(sha256.New, []byte("salt"))
+	mac.Write(a1[:])
+	require.Equal(t, "anon/"+hex.EncodeToString(mac.Sum(nil)[:8]), anon)
+}
+
+func TestAgentError(t *testing.T) {
+	agent := NewRandomAgentID()
+	err := fmt.Errorf("batch failed: %w", &AgentError{Agent: agent, Err: ErrWrongAgentKind})
+
+	var agentErr *AgentError
+	require.True(t, errors.As(err, &agentErr))
+	require.EqualValues(t, agent, agentErr.Agent)
+	require.True(t, errors.Is(err, ErrWrongAgentKind))
+	require.Contains(t, err.Error(), agent.String())
+}
+
+func TestFlagValues(t *testing.T) {
+	agentID := NewRandomAgentID()
+	chainID := NewRandomChainID()
+	contractID := NewContractID(chainID, Hn("test"))
+
+	var agentIDFlag AgentIDValue
+	var agentIDB58Flag AgentIDValue
+	var chainIDFlag ChainIDValue
+	var contractIDFlag ContractIDValue
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Var(&agentIDFlag, "agent", "agent ID")
+	flags.Var(&agentIDB58Flag, "agent58", "agent ID in base58")
+	flags.Var(&chainIDFlag, "chain", "chain ID")
+	flags.Var(&contractIDFlag, "contract", "contract ID")
+
+	err := flags.Parse([]string{
+		"-agent", agentID.String(),
+		"-agent58", agentID.Base58(),
+		"-chain", chainID.String(),
+		"-contract", contractID.String(),
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, agentID, AgentID(agentIDFlag))
+	require.EqualValues(t, agentID, AgentID(agentIDB58Flag))
+	require.EqualValues(t, chainID, ChainID(chainIDFlag))
+	require.EqualValues(t, contractID, ContractID(contractIDFlag))
+	require.EqualValues(t, agentID.String(), agentIDFlag.String())
+
+	require.Error(t, chainIDFlag.Set("wrong"))
+}
+
+func TestNormalizeAgentIDString(t *testing.T) {
+	for _, a := range []AgentID{NewAgentIDFromAddress(address.Random()), NewRandomAgentID()} {
+		fromPrefixed, err := NormalizeAgentIDString(a.String())
+		require.NoError(t, err)
+		fromBase58, err := NormalizeAgentIDString(" " + a.Base58() + "\n")
+		require.NoError(t, err)
+		require.EqualValues(t, a.String(), fromPrefixed)
+		require.EqualValues(t, fromPrefixed, fromBase58)
+
+		_, err = NormalizeAgentIDString(a.String() + " " + a.Base58())
+		require.Error(t, err)
+	}
+	_, err := NormalizeAgentIDString("X/wrong")
+	require.Error(t, err)
+	_, err = NormalizeAgentIDString("")
+	require.Error(t, err)
+}
+
+func TestScanAgentID(t *testing.T) {
+	addrAgent := NewAgentIDFromAddress(address.Random())
+	contractAgent := NewRandomAgentID()
+
+	line := "  " + addrAgent.String() + " " + contractAgent.String() + "\t" + contractAgent.Base58() + "  transferred 5 iotas\n"
+
+	a, rest, err := ScanAgentID(line)
+	require.NoError(t, err)
+	require.EqualValues(t, addrAgent, a)
+
+	a, rest, err = ScanAgentID(rest)
+	require.NoError(t, err)
+	require.EqualValues(t, contractAgent, a)
+
+	a, rest, err = ScanAgentID(rest)
+	require.NoError(t, err)
+	require.EqualValues(t, contractAgent, a)
+	require.EqualValues(t, "transferred 5 iotas\n", rest)
+
+	_, _, err = ScanAgentID(rest)
+	require.Error(t, err)
+
+	a, rest, err = ScanAgentID(addrAgent.String())
+	require.NoError(t, err)
+	require.EqualValues(t, addrAgent, a)
+	require.EqualValues(t, "", rest)
+
+	_, _, err = ScanAgentID("   ")
+	require.Error(t, err)
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package coretypes
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/mr-tron/base58"
+)
+
+// Encoding selects the text encoding of binary IDs
+type Encoding int
+
+const (
+	// EncodingBase58 is the default encoding, same as used by Base58()
+	EncodingBase58 = Encoding(iota)
+	// EncodingBase64URL is the unpadded base64 encoding with the URL and file name safe alphabet (RFC 4648)
+	EncodingBase64URL
+)
+
+func (enc Encoding) String() string {
+	switch enc {
+	case EncodingBase58:
+		return "base58"
+	case EncodingBase64URL:
+		return "base64url"
+	}
+	return fmt.Sprintf("Encoding(%d)", int(enc))
+}
+
+func (enc Encoding) encode(data []byte) string {
+	switch enc {
+	case EncodingBase58:
+		return base58.Encode(data)
+	case EncodingBase64URL:
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	panic(fmt.Sprintf("unknown encoding %s", enc))
+}
+
+func (enc Encoding) decode(s string) ([]byte, error) {
+	switch enc {
+	case EncodingBase58:
+		return base58.Decode(s)
+	case EncodingBase64URL:
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return nil, fmt.Errorf("unknown encoding %s", enc)
+}
+
+// Encode returns the binary representation of the agent ID in the given encoding
+func (a AgentID) Encode(enc Encoding) string {
+	return enc.encode(a[:])
+}
+
+// DecodeAgentID decodes the agent ID encoded with AgentID.Encode
+func DecodeAgentID(s string, enc Encoding) (ret AgentID, err error) {
+	var data []byte
+	if data, err = enc.decode(s); err != nil {
+		return
+	}
+	return NewAgentIDFromBytes(data)
+}
@@ -3,8 +3,34 @@
 
 package coretypes
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrWrongDataLength = errors.New("wrong data length")
+	ErrWrongAgentKind  = errors.New("wrong kind of agent ID")
+	ErrAddressVersion  = errors.New("unsupported address version")
 )
+
+// wrongDataLength wraps ErrWrongDataLength with the expected and the actual length of the data,
+// so errors.Is(err, ErrWrongDataLength) still holds
+func wrongDataLength(expected, actual int) error {
+	return fmt.Errorf("%w: expected %d bytes, got %d", ErrWrongDataLength, expected, actual)
+}
+
+// AgentError is the error of an operation which failed on a specific agent.
+// Use errors.As to find out which agent failed
+type AgentError struct {
+	Agent AgentID
+	Err   error
+}
+
+func (e *AgentError) Error() string {
+	return fmt.Sprintf("agent %s: %v", e.Agent.String(), e.Err)
+}
+
+func (e *AgentError) Unwrap() error {
+	return e.Err
+}
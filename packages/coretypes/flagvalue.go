@@ -0,0 +1,65 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package coretypes
+
+// AgentIDValue implements flag.Value for AgentID. It accepts the human-readable form
+// (see AgentID.String) and the base58 form
+type AgentIDValue AgentID
+
+func (v *AgentIDValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return AgentID(*v).String()
+}
+
+func (v *AgentIDValue) Set(s string) error {
+	a, err := NewAgentIDFromString(s)
+	if err != nil {
+		if a, err = DecodeAgentID(s, EncodingBase58); err != nil {
+			return err
+		}
+	}
+	*v = AgentIDValue(a)
+	return nil
+}
+
+// ChainIDValue implements flag.Value for ChainID in base58 form
+type ChainIDValue ChainID
+
+func (v *ChainIDValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return ChainID(*v).String()
+}
+
+func (v *ChainIDValue) Set(s string) error {
+	chid, err := NewChainIDFromBase58(s)
+	if err != nil {
+		return err
+	}
+	*v = ChainIDValue(chid)
+	return nil
+}
+
+// ContractIDValue implements flag.Value for ContractID in the human-readable form (see ContractID.String)
+type ContractIDValue ContractID
+
+func (v *ContractIDValue) String() string {
+	if v == nil {
+		return ""
+	}
+	cid := ContractID(*v)
+	return cid.String()
+}
+
+func (v *ContractIDValue) Set(s string) error {
+	cid, err := NewContractIDFromString(s)
+	if err != nil {
+		return err
+	}
+	*v = ContractIDValue(cid)
+	return nil
+}
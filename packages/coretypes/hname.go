@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/pkg/errors"
@@ -49,11 +50,17 @@ func (hn Hname) Bytes() []byte {
 	return ret
 }
 
+// String returns the canonical form of the hname: exactly 8 lowercase hex digits
 func (hn Hname) String() string {
-	return fmt.Sprintf("%08x", (int)(hn))
+	return fmt.Sprintf("%08x", (uint32)(hn))
 }
 
+// HnameFromString parses the canonical form returned by Hname.String. Other forms of the number,
+// e.g. without leading zeros or in uppercase, are rejected, so the string form of an hname is unique
 func HnameFromString(s string) (Hname, error) {
+	if len(s) != 2*HnameLength || strings.ToLower(s) != s {
+		return 0, errors.Errorf("cannot parse hname: '%s' is not 8 lowercase hex digits", s)
+	}
 	n, err := strconv.ParseUint(s, 16, 32)
 	if err != nil {
 		return 0, errors.Wrap(err, "cannot parse hname")
@@ -73,7 +80,7 @@ func (hn *Hname) Read(r io.Reader) error {
 		return err
 	}
 	if n != HnameLength {
-		return ErrWrongDataLength
+		return wrongDataLength(HnameLength, n)
 	}
 	t := binary.LittleEndian.Uint32(b[:])
 	*hn = (Hname)(t)
@@ -0,0 +1,73 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package coretypes
+
+import (
+	"fmt"
+	"sync"
+)
+
+// HnameRegistry is a reverse lookup table hname -> name.
+// Hname is a hash, so the name can't be recovered from it. The registry is a development aid
+// to make hnames in logs readable
+type HnameRegistry struct {
+	mutex sync.RWMutex
+	names map[Hname]string
+}
+
+// NewHnameRegistry creates a new empty registry
+func NewHnameRegistry() *HnameRegistry {
+	return &HnameRegistry{names: make(map[Hname]string)}
+}
+
+// Register adds names to the registry and returns hname of the last one
+func (r *HnameRegistry) Register(names ...string) (ret Hname) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, name := range names {
+		ret = Hn(name)
+		r.names[ret] = name
+	}
+	return
+}
+
+// Resolve returns the name registered for the hname
+func (r *HnameRegistry) Resolve(hn Hname) (string, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	name, ok := r.names[hn]
+	return name, ok
+}
+
+var (
+	defaultHnameRegistry      *HnameRegistry
+	defaultHnameRegistryMutex sync.RWMutex
+)
+
+// SetHnameRegistry sets the registry used by ResolveHname and Hname.Readable. nil disables the lookup
+func SetHnameRegistry(r *HnameRegistry) {
+	defaultHnameRegistryMutex.Lock()
+	defer defaultHnameRegistryMutex.Unlock()
+	defaultHnameRegistry = r
+}
+
+// ResolveHname looks up the hname in the registry set by SetHnameRegistry
+func ResolveHname(hn Hname) (string, bool) {
+	defaultHnameRegistryMutex.RLock()
+	r := defaultHnameRegistry
+	defaultHnameRegistryMutex.RUnlock()
+	if r == nil {
+		return "", false
+	}
+	return r.Resolve(hn)
+}
+
+// Readable returns 'name(hex)' if the name of the hname is known, otherwise the same as String.
+// Unlike String, the result is not intended to be parsed back
+func (hn Hname) Readable() string {
+	if name, ok := ResolveHname(hn); ok {
+		return fmt.Sprintf("%s(%s)", name, hn.String())
+	}
+	return hn.String()
+}
@@ -67,7 +67,7 @@ func (rid *RequestID) Read(r io.Reader) error {
 		return err
 	}
 	if n != RequestIDLength {
-		return ErrWrongDataLength
+		return wrongDataLength(RequestIDLength, n)
 	}
 	return nil
 }
@@ -174,7 +174,9 @@ func (ms *mutationSequence) Clone() MutationSequence {
 	for k, v := range ms.latestByKey {
 		mapClone[k] = v
 	}
-	return &mutationSequence{muts: ms.muts[:], latestByKey: mapClone}
+	// the capacity is limited to the length, so appending to the clone or to the original
+	// reallocates instead of overwriting the mutations of the other one in the shared array
+	return &mutationSequence{muts: ms.muts[:len(ms.muts):len(ms.muts)], latestByKey: mapClone}
 }
 
 type mutationSet struct {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/util"
 	"github.com/stretchr/testify/assert"
@@ -51,3 +52,26 @@ func TestMutationSequenceMarshaling(t *testing.T) {
 
 	assert.EqualValues(t, util.GetHashValue(ms), util.GetHashValue(ms2))
 }
+
+func TestMutationSequenceClone(t *testing.T) {
+	ms := NewMutationSequence()
+	for _, k := range []kv.Key{"k1", "k2", "k3"} {
+		ms.Add(NewMutationSet(k, []byte("v")))
+	}
+	clone := ms.Clone()
+	ms.Add(NewMutationSet("k4", []byte("v")))
+	clone.Add(NewMutationSet("k5", []byte("v")))
+
+	keys := func(ms MutationSequence) []kv.Key {
+		ret := make([]kv.Key, 0)
+		ms.Iterate(func(mut Mutation) bool {
+			ret = append(ret, mut.Key())
+			return true
+		})
+		return ret
+	}
+	assert.Equal(t, []kv.Key{"k1", "k2", "k3", "k4"}, keys(ms))
+	assert.Equal(t, []kv.Key{"k1", "k2", "k3", "k5"}, keys(clone))
+	assert.Nil(t, ms.Latest("k5"))
+	assert.Nil(t, clone.Latest("k4"))
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/util"
 )
@@ -224,3 +225,21 @@ func (m *ImmutableMap) IterateBalances(f func(color balance.Color, bal int64) bo
 	})
 	return err
 }
+
+// GetAtAgentID is GetAt with the agent ID as the key. The key is AgentID.Bytes()
+func (m *ImmutableMap) GetAtAgentID(agentID coretypes.AgentID) ([]byte, error) {
+	return m.GetAt(agentID.Bytes())
+}
+
+func (m *ImmutableMap) MustGetAtAgentID(agentID coretypes.AgentID) []byte {
+	return m.MustGetAt(agentID.Bytes())
+}
+
+// SetAtAgentID is SetAt with the agent ID as the key. The key is AgentID.Bytes()
+func (m *Map) SetAtAgentID(agentID coretypes.AgentID, value []byte) error {
+	return m.SetAt(agentID.Bytes(), value)
+}
+
+func (m *Map) MustSetAtAgentID(agentID coretypes.AgentID, value []byte) {
+	m.MustSetAt(agentID.Bytes(), value)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"testing"
 
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/stretchr/testify/assert"
 )
@@ -78,6 +79,35 @@ func TestBasicMap(t *testing.T) {
 	assert.EqualValues(t, v3, v)
 }
 
+func TestMapAgentIDKeys(t *testing.T) {
+	vars := dict.New()
+	m := NewMap(vars, "testMap")
+
+	agents := []coretypes.AgentID{coretypes.NewRandomAgentID(), coretypes.NewRandomAgentID()}
+	for i, a := range agents {
+		require.NoError(t, m.SetAtAgentID(a, []byte{byte(i)}))
+	}
+	require.EqualValues(t, 2, m.MustLen())
+	for i, a := range agents {
+		v, err := m.GetAtAgentID(a)
+		require.NoError(t, err)
+		require.Equal(t, []byte{byte(i)}, v)
+		require.Equal(t, v, m.MustGetAt(a.Bytes()))
+	}
+
+	m.MustSetAtAgentID(agents[0], []byte("updated"))
+	require.EqualValues(t, 2, m.MustLen())
+	require.Equal(t, []byte("updated"), m.Immutable().MustGetAtAgentID(agents[0]))
+	require.Nil(t, m.MustGetAtAgentID(coretypes.NewRandomAgentID()))
+
+	m.MustIterateKeys(func(elemKey []byte) bool {
+		a, err := coretypes.NewAgentIDFromBytes(elemKey)
+		require.NoError(t, err)
+		require.True(t, a == agents[0] || a == agents[1])
+		return true
+	})
+}
+
 func TestIterate(t *testing.T) {
 	vars := dict.New()
 	m := NewMap(vars, "testMap")
@@ -114,3 +144,28 @@ func TestMapConcurrentAccess(t *testing.T) {
 	require.EqualValues(t, m1.MustLen(), 0)
 	require.EqualValues(t, m2.MustLen(), 0)
 }
+
+func TestMapJSON(t *testing.T) {
+	vars := dict.New()
+	m := NewMap(vars, "testMap")
+	m.MustSetAt([]byte("k2"), []byte("datum2"))
+	m.MustSetAt([]byte("k1"), []byte("datum1"))
+
+	data, err := m.ToJSON()
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"key": "6b31"`)
+
+	vars2 := dict.New()
+	m2, err := MapFromJSON(vars2, data)
+	require.NoError(t, err)
+	require.EqualValues(t, "testMap", m2.Name())
+	require.EqualValues(t, vars, vars2)
+
+	data, err = m.ToJSON(func(_ []byte, value []byte) interface{} {
+		return string(value)
+	})
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"value": "datum1"`)
+	_, err = MapFromJSON(dict.New(), data)
+	require.Error(t, err)
+}
@@ -0,0 +1,85 @@
+package collections
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/iotaledger/wasp/packages/kv"
+)
+
+// ValueInterpreter renders the value of a map element in the JSON dump of the map, e.g. decoding known types
+type ValueInterpreter func(elemKey []byte, value []byte) interface{}
+
+// HexValue is the default ValueInterpreter, which renders the value as a hex string
+func HexValue(_ []byte, value []byte) interface{} {
+	return hex.EncodeToString(value)
+}
+
+type jsonMap struct {
+	Name    string         `json:"name"`
+	Entries []jsonMapEntry `json:"entries"`
+}
+
+type jsonMapEntry struct {
+	Key   string      `json:"key"` // hex-encoded
+	Value interface{} `json:"value"`
+}
+
+// ToJSON renders the map sorted by key, with hex-encoded keys and values rendered by the interpreter (HexValue
+// by default). It is a debugging aid for tooling: the whole map is loaded into memory
+func (m *ImmutableMap) ToJSON(interpret ...ValueInterpreter) ([]byte, error) {
+	interpretValue := ValueInterpreter(HexValue)
+	if len(interpret) > 0 && interpret[0] != nil {
+		interpretValue = interpret[0]
+	}
+	type entry struct{ key, value []byte }
+	entries := make([]entry, 0)
+	err := m.Iterate(func(elemKey []byte, value []byte) bool {
+		entries = append(entries, entry{key: append([]byte(nil), elemKey...), value: value})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+	ret := jsonMap{Name: m.name, Entries: make([]jsonMapEntry, len(entries))}
+	for i, e := range entries {
+		ret.Entries[i] = jsonMapEntry{
+			Key:   hex.EncodeToString(e.key),
+			Value: interpretValue(e.key, e.value),
+		}
+	}
+	return json.MarshalIndent(ret, "", "  ")
+}
+
+// MapFromJSON writes the map rendered by ToJSON into the store. Only maps rendered with HexValue can be read back
+func MapFromJSON(kvs kv.KVStore, data []byte) (*Map, error) {
+	var j jsonMap
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	m := NewMap(kvs, j.Name)
+	for _, e := range j.Entries {
+		key, err := hex.DecodeString(e.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %v", e.Key, err)
+		}
+		s, ok := e.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("value of key %q is not a hex string", e.Key)
+		}
+		value, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of key %q: %v", e.Key, err)
+		}
+		if err := m.SetAt(key, value); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
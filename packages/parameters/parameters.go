@@ -19,6 +19,8 @@ const (
 	WebAPIBindAddress    = "webapi.bindAddress"
 	WebAPIAdminWhitelist = "webapi.adminWhitelist"
 	WebAPIAuth           = "webapi.auth"
+	WebAPIStateReaders   = "webapi.stateReaders"
+	WebAPIRequestTrace   = "webapi.requestTrace"
 
 	DashboardBindAddress       = "dashboard.bindAddress"
 	DashboardExploreAddressUrl = "dashboard.exploreAddressUrl"
@@ -46,6 +48,8 @@ func InitFlags() {
 	flag.String(WebAPIBindAddress, "127.0.0.1:8080", "the bind address for the web API")
 	flag.StringSlice(WebAPIAdminWhitelist, []string{}, "IP whitelist for /adm wndpoints")
 	flag.StringToString(WebAPIAuth, nil, "authentication scheme for web API")
+	flag.StringSlice(WebAPIStateReaders, []string{}, "agent IDs (e.g. A/<address>) allowed to query the state with signed requests")
+	flag.Bool(WebAPIRequestTrace, false, "serve the traces of the processed requests, including their state mutations. Ignored when webapi.stateReaders is set")
 
 	flag.String(DashboardBindAddress, "127.0.0.1:7000", "the bind address for the node dashboard")
 	flag.String(DashboardExploreAddressUrl, "", "URL to add as href to addresses in the dashboard [default: <nodeconn.address>:8081/explorer/address]")
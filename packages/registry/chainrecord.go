@@ -2,10 +2,12 @@ package registry
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"github.com/iotaledger/wasp/packages/dbprovider"
 	"io"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/hive.go/kvstore"
 	"github.com/iotaledger/wasp/packages/coretypes"
@@ -22,6 +24,15 @@ type ChainRecord struct {
 	Color          balance.Color // origin tx hash
 	CommitteeNodes []string      // "host_addr:port"
 	Active         bool
+	// CommitteeOwners are the addresses of the operators of the committee nodes, optional.
+	// Committee nodes are identified only by their network address, so the owners must be
+	// listed explicitly to relate them to agents
+	CommitteeOwners []address.Address
+	// CommitteeWeights are the weights of the committee nodes in the order of CommitteeNodes, optional.
+	// If not empty, WeightedQuorum is the total weight of the nodes required for the quorum.
+	// Without weights, all nodes weigh the same
+	CommitteeWeights []uint32
+	WeightedQuorum   uint64
 }
 
 func dbkeyChainRecord(chainID *coretypes.ChainID) []byte {
@@ -35,6 +46,9 @@ func SaveChainRecord(bd *ChainRecord) error {
 	if bd.Color == balance.ColorNew || bd.Color == balance.ColorIOTA {
 		return fmt.Errorf("can't be IOTA or New color")
 	}
+	if err := bd.ValidateWeights(); err != nil {
+		return err
+	}
 	var buf bytes.Buffer
 	if err := bd.Write(&buf); err != nil {
 		return err
@@ -127,7 +141,23 @@ func (bd *ChainRecord) Write(w io.Writer) error {
 	if err := util.WriteBoolByte(w, bd.Active); err != nil {
 		return err
 	}
-	return nil
+	if err := util.WriteUint16(w, uint16(len(bd.CommitteeOwners))); err != nil {
+		return err
+	}
+	for i := range bd.CommitteeOwners {
+		if _, err := w.Write(bd.CommitteeOwners[i][:]); err != nil {
+			return err
+		}
+	}
+	if err := util.WriteUint16(w, uint16(len(bd.CommitteeWeights))); err != nil {
+		return err
+	}
+	for _, weight := range bd.CommitteeWeights {
+		if err := util.WriteUint32(w, weight); err != nil {
+			return err
+		}
+	}
+	return util.WriteUint64(w, bd.WeightedQuorum)
 }
 
 func (bd *ChainRecord) Read(r io.Reader) error {
@@ -144,6 +174,157 @@ func (bd *ChainRecord) Read(r io.Reader) error {
 	if err = util.ReadBoolByte(r, &bd.Active); err != nil {
 		return err
 	}
+	// records saved before the owners were introduced end here
+	var numOwners uint16
+	if err = util.ReadUint16(r, &numOwners); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	bd.CommitteeOwners = nil
+	if numOwners > 0 {
+		bd.CommitteeOwners = make([]address.Address, numOwners)
+	}
+	for i := range bd.CommitteeOwners {
+		if _, err = io.ReadFull(r, bd.CommitteeOwners[i][:]); err != nil {
+			return err
+		}
+	}
+	// records saved before the weights were introduced end here
+	var numWeights uint16
+	if err = util.ReadUint16(r, &numWeights); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	bd.CommitteeWeights = nil
+	if numWeights > 0 {
+		bd.CommitteeWeights = make([]uint32, numWeights)
+	}
+	for i := range bd.CommitteeWeights {
+		if err = util.ReadUint32(r, &bd.CommitteeWeights[i]); err != nil {
+			return err
+		}
+	}
+	return util.ReadUint64(r, &bd.WeightedQuorum)
+}
+
+// Clone returns a deep copy of the chain record
+func (bd *ChainRecord) Clone() *ChainRecord {
+	ret := *bd
+	ret.CommitteeNodes = make([]string, len(bd.CommitteeNodes))
+	copy(ret.CommitteeNodes, bd.CommitteeNodes)
+	if bd.CommitteeOwners != nil {
+		ret.CommitteeOwners = make([]address.Address, len(bd.CommitteeOwners))
+		copy(ret.CommitteeOwners, bd.CommitteeOwners)
+	}
+	if bd.CommitteeWeights != nil {
+		ret.CommitteeWeights = make([]uint32, len(bd.CommitteeWeights))
+		copy(ret.CommitteeWeights, bd.CommitteeWeights)
+	}
+	return &ret
+}
+
+// ValidateWeights checks the weighted quorum configuration: each committee node must have a positive weight
+// and the total weight of the committee must be enough to reach WeightedQuorum.
+// A record without weights is valid if WeightedQuorum is 0
+func (bd *ChainRecord) ValidateWeights() error {
+	if len(bd.CommitteeWeights) == 0 {
+		if bd.WeightedQuorum != 0 {
+			return fmt.Errorf("weighted quorum %d without committee weights", bd.WeightedQuorum)
+		}
+		return nil
+	}
+	if len(bd.CommitteeWeights) != len(bd.CommitteeNodes) {
+		return fmt.Errorf("%d committee weights for %d committee nodes", len(bd.CommitteeWeights), len(bd.CommitteeNodes))
+	}
+	total := uint64(0)
+	for i, weight := range bd.CommitteeWeights {
+		if weight == 0 {
+			return fmt.Errorf("zero weight of committee node %s", bd.CommitteeNodes[i])
+		}
+		total += uint64(weight)
+	}
+	if bd.WeightedQuorum == 0 || bd.WeightedQuorum > total {
+		return fmt.Errorf("invalid weighted quorum %d for total committee weight %d", bd.WeightedQuorum, total)
+	}
+	return nil
+}
+
+// WithCommittee returns a copy of the chain record with the new list of committee nodes.
+// The weights, the weighted quorum and the owners belong to the old committee, so they are cleared
+// and must be set again for the new one. The original record is not changed
+func (bd *ChainRecord) WithCommittee(nodes []string) *ChainRecord {
+	ret := bd.Clone()
+	ret.CommitteeNodes = make([]string, len(nodes))
+	copy(ret.CommitteeNodes, nodes)
+	ret.CommitteeOwners = nil
+	ret.CommitteeWeights = nil
+	ret.WeightedQuorum = 0
+	return ret
+}
+
+// Equals compares the configuration of the chain: ChainID, Color, the committee nodes, their owners
+// and their weights, in order, and the weighted quorum.
+// Active is the state of the chain in the node, so it is ignored
+func (bd *ChainRecord) Equals(other *ChainRecord) bool {
+	if bd == other {
+		return true
+	}
+	if bd == nil || other == nil {
+		return false
+	}
+	if bd.ChainID != other.ChainID || bd.Color != other.Color || bd.WeightedQuorum != other.WeightedQuorum {
+		return false
+	}
+	if len(bd.CommitteeNodes) != len(other.CommitteeNodes) ||
+		len(bd.CommitteeOwners) != len(other.CommitteeOwners) ||
+		len(bd.CommitteeWeights) != len(other.CommitteeWeights) {
+		return false
+	}
+	for i := range bd.CommitteeNodes {
+		if bd.CommitteeNodes[i] != other.CommitteeNodes[i] {
+			return false
+		}
+	}
+	for i := range bd.CommitteeOwners {
+		if bd.CommitteeOwners[i] != other.CommitteeOwners[i] {
+			return false
+		}
+	}
+	for i := range bd.CommitteeWeights {
+		if bd.CommitteeWeights[i] != other.CommitteeWeights[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsCommitteeMember returns true if the agent is the address of the operator of one of the committee nodes,
+// as listed in CommitteeOwners. Contract agents are never committee members
+func (bd *ChainRecord) IsCommitteeMember(a coretypes.AgentID) bool {
+	if !a.IsAddress() {
+		return false
+	}
+	addr := a.MustAddress()
+	for i := range bd.CommitteeOwners {
+		if bd.CommitteeOwners[i] == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrNotCommitteeMember is the reason of the AgentError returned by CheckCommitteeMember
+var ErrNotCommitteeMember = errors.New("not an owner of a committee node")
+
+// CheckCommitteeMember returns an AgentError wrapping ErrNotCommitteeMember if the agent is not a committee member
+func (bd *ChainRecord) CheckCommitteeMember(a coretypes.AgentID) error {
+	if !bd.IsCommitteeMember(a) {
+		return &coretypes.AgentError{Agent: a, Err: ErrNotCommitteeMember}
+	}
 	return nil
 }
 
@@ -0,0 +1,154 @@
+package registry
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainRecordWithCommittee(t *testing.T) {
+	rec := &ChainRecord{
+		ChainID:          coretypes.NewRandomChainID(),
+		Color:            balance.Color{1, 2, 3},
+		CommitteeNodes:   []string{"wasp1:4000", "wasp2:4000", "wasp3:4000"},
+		Active:           true,
+		CommitteeOwners:  []address.Address{address.Random()},
+		CommitteeWeights: []uint32{1, 2, 3},
+		WeightedQuorum:   4,
+	}
+	orig := rec.Clone()
+
+	nodes := []string{"wasp1:4000", "wasp2:4000", "wasp4:4000"}
+	rotated := rec.WithCommittee(nodes)
+	require.EqualValues(t, orig, rec)
+	require.EqualValues(t, rec.ChainID, rotated.ChainID)
+	require.EqualValues(t, rec.Color, rotated.Color)
+	require.EqualValues(t, nodes, rotated.CommitteeNodes)
+	// the weights and the owners of the old committee don't apply to the new one
+	require.Empty(t, rotated.CommitteeOwners)
+	require.Empty(t, rotated.CommitteeWeights)
+	require.Zero(t, rotated.WeightedQuorum)
+	require.NoError(t, rotated.ValidateWeights())
+
+	nodes[0] = "changed:4000"
+	rotated.CommitteeNodes[1] = "changed:4000"
+	require.EqualValues(t, orig, rec)
+	require.EqualValues(t, "wasp1:4000", rotated.CommitteeNodes[0])
+}
+
+func TestChainRecordEquals(t *testing.T) {
+	rec := &ChainRecord{
+		ChainID:        coretypes.NewRandomChainID(),
+		Color:          balance.Color{1, 2, 3},
+		CommitteeNodes: []string{"wasp1:4000", "wasp2:4000", "wasp3:4000"},
+		Active:         true,
+	}
+	inactive := rec.Clone()
+	inactive.Active = false
+	require.True(t, rec.Equals(inactive))
+
+	require.False(t, rec.Equals(rec.WithCommittee([]string{"wasp1:4000", "wasp3:4000", "wasp2:4000"})))
+	otherColor := rec.Clone()
+	otherColor.Color = balance.Color{4}
+	require.False(t, rec.Equals(otherColor))
+	require.False(t, rec.Equals(nil))
+
+	withOwners := rec.Clone()
+	withOwners.CommitteeOwners = []address.Address{address.Random()}
+	require.False(t, rec.Equals(withOwners))
+	weighted := rec.Clone()
+	weighted.CommitteeWeights = []uint32{1, 1, 1}
+	weighted.WeightedQuorum = 2
+	require.False(t, rec.Equals(weighted))
+	otherQuorum := weighted.Clone()
+	otherQuorum.WeightedQuorum = 3
+	require.False(t, weighted.Equals(otherQuorum))
+	otherWeights := weighted.Clone()
+	otherWeights.CommitteeWeights[2] = 2
+	require.False(t, weighted.Equals(otherWeights))
+	require.True(t, weighted.Equals(weighted.Clone()))
+}
+
+func TestChainRecordIsCommitteeMember(t *testing.T) {
+	owner := address.Random()
+	rec := &ChainRecord{
+		ChainID:         coretypes.NewRandomChainID(),
+		Color:           balance.Color{1, 2, 3},
+		CommitteeNodes:  []string{"wasp1:4000", "wasp2:4000"},
+		CommitteeOwners: []address.Address{address.Random(), owner},
+	}
+	require.True(t, rec.IsCommitteeMember(coretypes.NewAgentIDFromAddress(owner)))
+	require.False(t, rec.IsCommitteeMember(coretypes.NewAgentIDFromAddress(address.Random())))
+	require.False(t, rec.IsCommitteeMember(coretypes.NewAgentIDFromContractID(coretypes.NewContractID(rec.ChainID, 1))))
+
+	require.NoError(t, rec.CheckCommitteeMember(coretypes.NewAgentIDFromAddress(owner)))
+	stranger := coretypes.NewAgentIDFromAddress(address.Random())
+	err := rec.CheckCommitteeMember(stranger)
+	var agentErr *coretypes.AgentError
+	require.True(t, errors.As(err, &agentErr))
+	require.EqualValues(t, stranger, agentErr.Agent)
+	require.True(t, errors.Is(err, ErrNotCommitteeMember))
+
+	var buf bytes.Buffer
+	require.NoError(t, rec.Write(&buf))
+	back := new(ChainRecord)
+	require.NoError(t, back.Read(bytes.NewReader(buf.Bytes())))
+	require.EqualValues(t, rec, back)
+	require.True(t, back.IsCommitteeMember(coretypes.NewAgentIDFromAddress(owner)))
+
+	// record without owners, as saved before they were introduced
+	buf.Reset()
+	rec.CommitteeOwners = nil
+	require.NoError(t, rec.Write(&buf))
+	back = new(ChainRecord)
+	// without the number of owners, the number of weights and the weighted quorum
+	require.NoError(t, back.Read(bytes.NewReader(buf.Bytes()[:buf.Len()-2-2-8])))
+	require.EqualValues(t, rec, back)
+	require.False(t, back.IsCommitteeMember(coretypes.NewAgentIDFromAddress(owner)))
+}
+
+func TestChainRecordWeights(t *testing.T) {
+	rec := &ChainRecord{
+		ChainID:        coretypes.NewRandomChainID(),
+		Color:          balance.Color{1, 2, 3},
+		CommitteeNodes: []string{"wasp1:4000", "wasp2:4000", "wasp3:4000"},
+	}
+	require.NoError(t, rec.ValidateWeights())
+	rec.WeightedQuorum = 2
+	require.Error(t, rec.ValidateWeights())
+
+	rec.CommitteeWeights = []uint32{1, 2, 3}
+	rec.WeightedQuorum = 4
+	require.NoError(t, rec.ValidateWeights())
+	rec.WeightedQuorum = 7
+	require.Error(t, rec.ValidateWeights())
+	rec.WeightedQuorum = 0
+	require.Error(t, rec.ValidateWeights())
+	rec.WeightedQuorum = 4
+	rec.CommitteeWeights = []uint32{1, 2}
+	require.Error(t, rec.ValidateWeights())
+	rec.CommitteeWeights = []uint32{1, 0, 3}
+	require.Error(t, rec.ValidateWeights())
+	rec.CommitteeWeights = []uint32{1, 2, 3}
+
+	var buf bytes.Buffer
+	require.NoError(t, rec.Write(&buf))
+	back := new(ChainRecord)
+	require.NoError(t, back.Read(bytes.NewReader(buf.Bytes())))
+	require.EqualValues(t, rec, back)
+	require.EqualValues(t, rec, back.Clone())
+
+	// record without weights, as saved before they were introduced
+	buf.Reset()
+	rec.CommitteeWeights = nil
+	rec.WeightedQuorum = 0
+	require.NoError(t, rec.Write(&buf))
+	back = new(ChainRecord)
+	require.NoError(t, back.Read(bytes.NewReader(buf.Bytes()[:buf.Len()-2-8])))
+	require.EqualValues(t, rec, back)
+}
@@ -119,6 +119,17 @@ func (tx *Transaction) Sender() *address.Address {
 	return &ret
 }
 
+// RequestID returns the ID of the request in the section with the given index of the transaction with the given ID,
+// as the node identifies it
+func RequestID(txID valuetransaction.ID, index uint16) coretypes.RequestID {
+	return coretypes.NewRequestID(txID, index)
+}
+
+// RequestID returns the ID of the request in the section with the given index
+func (tx *Transaction) RequestID(index uint16) coretypes.RequestID {
+	return RequestID(tx.ID(), index)
+}
+
 func (tx *Transaction) OutputBalancesByAddress(addr address.Address) ([]*balance.Balance, bool) {
 	untyped, ok := tx.Outputs().Get(addr)
 	if !ok {
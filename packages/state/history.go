@@ -0,0 +1,95 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+var (
+	// ErrStateIndexPruned is returned when the historical state can't be reconstructed: some of the blocks needed
+	// are not in the db, or there are more of them than are applied for one query
+	ErrStateIndexPruned = errors.New("state index is pruned")
+	// ErrStateIndexNotReached is returned when the requested state index is above the solid state index
+	ErrStateIndexNotReached = errors.New("state index is not reached yet")
+)
+
+// LoadStateAtIndex returns the state of the chain as it was after the block with the given state index.
+// Past states are not stored: they are reconstructed in memory by applying blocks, starting from the origin
+// or from the nearest preceding state kept in memory by previous calls. Every historyCheckpointInterval-th
+// state on the way is kept, so the following calls for nearby states apply at most historyCheckpointInterval
+// blocks, and the last reconstructed states are kept as they are, so repeated queries of the same state apply none.
+// The kept states are bounded by their size in memory, and concurrent calls for the same state share
+// one reconstruction.
+// At most historyMaxReplayedBlocks blocks are applied in one call: states further from the origin and from the
+// kept states are not reconstructed and ErrStateIndexPruned is returned, as it is when any of the needed blocks
+// was pruned from the db
+func LoadStateAtIndex(chainID *coretypes.ChainID, stateIndex uint32) (VirtualState, Block, error) {
+	return loadStateAtIndex(getSCPartition(chainID), pastStates, chainID, stateIndex, historyMaxReplayedBlocks)
+}
+
+// loadStateAtIndex reconstructs the past state, applying at most maxReplayedBlocks blocks. cache may be nil
+func loadStateAtIndex(db kvstore.KVStore, cache *historyCache, chainID *coretypes.ChainID, stateIndex uint32, maxReplayedBlocks uint32) (VirtualState, Block, error) {
+	stateIndexBin, err := db.Get(dbprovider.MakeKey(dbprovider.ObjectTypeSolidStateIndex))
+	if err == kvstore.ErrKeyNotFound {
+		return nil, nil, fmt.Errorf("%w: #%d, chain has no solid state", ErrStateIndexNotReached, stateIndex)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	solidIndex := util.MustUint32From4Bytes(stateIndexBin)
+	if stateIndex > solidIndex {
+		return nil, nil, fmt.Errorf("%w: #%d, solid state is #%d", ErrStateIndexNotReached, stateIndex, solidIndex)
+	}
+	if stateIndex == solidIndex {
+		vs, block, _, err := loadSolidState(db, chainID)
+		return vs, block, err
+	}
+	return cache.reconstruct(chainID, stateIndex, func() (VirtualState, Block, error) {
+		return replayBlocks(db, cache, chainID, stateIndex, maxReplayedBlocks)
+	})
+}
+
+// replayBlocks applies the blocks to the nearest kept state, or to the empty state, up to stateIndex
+func replayBlocks(db kvstore.KVStore, cache *historyCache, chainID *coretypes.ChainID, stateIndex uint32, maxReplayedBlocks uint32) (VirtualState, Block, error) {
+	var vs VirtualState
+	var block Block
+	from := uint32(0)
+	if past := cache.nearest(chainID, stateIndex); past != nil {
+		if past.vs.BlockIndex() == stateIndex {
+			return past.vs, past.block, nil
+		}
+		vs, block, from = past.vs, past.block, past.vs.BlockIndex()+1
+	} else {
+		vs = NewVirtualState(mapdb.NewMapDB(), chainID)
+	}
+	if stateIndex-from >= maxReplayedBlocks {
+		return nil, nil, fmt.Errorf("%w: #%d, %d blocks would be applied from #%d, at most %d are",
+			ErrStateIndexPruned, stateIndex, stateIndex-from+1, from, maxReplayedBlocks)
+	}
+	for i := from; i <= stateIndex; i++ {
+		data, err := db.Get(dbkeyBatch(i))
+		if err == kvstore.ErrKeyNotFound {
+			return nil, nil, fmt.Errorf("%w: #%d, block #%d is missing", ErrStateIndexPruned, stateIndex, i)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if block, err = NewBlockFromBytes(data); err != nil {
+			return nil, nil, fmt.Errorf("loading block #%d: %v", i, err)
+		}
+		if err = vs.ApplyBlock(block); err != nil {
+			return nil, nil, err
+		}
+		if i < stateIndex {
+			cache.putCheckpoint(chainID, vs, block, stateIndex)
+		}
+	}
+	cache.put(chainID, vs, block)
+	return vs, block, nil
+}
@@ -0,0 +1,210 @@
+package state
+
+import (
+	"sync"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+)
+
+const (
+	// historyCheckpointInterval is the distance between the states kept while a past state is reconstructed
+	historyCheckpointInterval = 100
+	// historyCheckpointBytes is the size of the checkpoint states kept in memory, over all chains
+	historyCheckpointBytes = 128 * 1024 * 1024
+	// historyStateBytes is the size of the reconstructed past states kept in memory, over all chains
+	historyStateBytes = 32 * 1024 * 1024
+	// historyMaxReplayedBlocks is the maximum number of blocks applied to reconstruct one past state
+	historyMaxReplayedBlocks = 10 * historyCheckpointInterval
+)
+
+// pastStates keeps the past states reconstructed by LoadStateAtIndex
+var pastStates = newHistoryCache(historyCheckpointInterval, historyCheckpointBytes, historyStateBytes)
+
+// historyCache keeps past states in memory, so they don't have to be reconstructed from the origin each time.
+// Checkpoints are the states with the index divisible by checkpointInterval, kept on the way to a reconstructed
+// state. They are kept apart from the reconstructed states, so walking back through the history one state
+// at a time doesn't evict the checkpoints the walk still needs.
+// It also tracks the reconstructions in progress, so concurrent queries of the same past state share one.
+// All methods are no-ops on a nil historyCache
+type historyCache struct {
+	mutex              sync.Mutex
+	checkpointInterval uint32
+	checkpoints        pastStateList
+	states             pastStateList
+	inProgress         map[pastStateKey]*reconstruction
+}
+
+type pastStateKey struct {
+	chainID    coretypes.ChainID
+	stateIndex uint32
+}
+
+type pastState struct {
+	chainID coretypes.ChainID
+	vs      VirtualState
+	block   Block
+	size    int
+}
+
+// pastStateList is a list of past states of bounded total size in bytes, the least recently used first
+type pastStateList struct {
+	maxBytes int
+	bytes    int
+	entries  []*pastState
+}
+
+// reconstruction is a past state being reconstructed. done is closed when the result is set
+type reconstruction struct {
+	done  chan struct{}
+	vs    VirtualState
+	block Block
+	err   error
+}
+
+func newHistoryCache(checkpointInterval uint32, checkpointBytes, stateBytes int) *historyCache {
+	return &historyCache{
+		checkpointInterval: checkpointInterval,
+		checkpoints:        pastStateList{maxBytes: checkpointBytes},
+		states:             pastStateList{maxBytes: stateBytes},
+		inProgress:         make(map[pastStateKey]*reconstruction),
+	}
+}
+
+// stateSize is the approximate memory taken by the past state: past states are kept in memory as the sequence
+// of all mutations applied to them
+func stateSize(vs VirtualState) int {
+	ret := 0
+	vs.Variables().Mutations().Iterate(func(mut buffered.Mutation) bool {
+		ret += len(mut.Key()) + len(mut.Value())
+		return true
+	})
+	return ret
+}
+
+// reconstruct returns a copy of the state reconstructed by f. Concurrent calls for the same state wait for
+// the call in progress and share its result instead of applying the same blocks again
+func (c *historyCache) reconstruct(chainID *coretypes.ChainID, stateIndex uint32, f func() (VirtualState, Block, error)) (VirtualState, Block, error) {
+	if c == nil {
+		return f()
+	}
+	key := pastStateKey{chainID: *chainID, stateIndex: stateIndex}
+	c.mutex.Lock()
+	r, inProgress := c.inProgress[key]
+	if !inProgress {
+		r = &reconstruction{done: make(chan struct{})}
+		c.inProgress[key] = r
+	}
+	c.mutex.Unlock()
+
+	if inProgress {
+		<-r.done
+	} else {
+		func() {
+			defer func() {
+				c.mutex.Lock()
+				delete(c.inProgress, key)
+				c.mutex.Unlock()
+				close(r.done)
+			}()
+			r.vs, r.block, r.err = f()
+		}()
+	}
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	// the result is shared, so each caller gets its own copy
+	return r.vs.Clone(), r.block, nil
+}
+
+// nearest returns a copy of the kept state of the chain with the greatest index not above stateIndex, or nil
+func (c *historyCache) nearest(chainID *coretypes.ChainID, stateIndex uint32) *pastState {
+	if c == nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ret := c.states.nearest(chainID, stateIndex)
+	if cp := c.checkpoints.nearest(chainID, stateIndex); ret == nil || (cp != nil && cp.vs.BlockIndex() > ret.vs.BlockIndex()) {
+		ret = cp
+	}
+	if ret == nil {
+		return nil
+	}
+	return &pastState{chainID: ret.chainID, vs: ret.vs.Clone(), block: ret.block, size: ret.size}
+}
+
+// put keeps a copy of the reconstructed state
+func (c *historyCache) put(chainID *coretypes.ChainID, vs VirtualState, block Block) {
+	if c == nil {
+		return
+	}
+	size := stateSize(vs)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.states.put(&pastState{chainID: *chainID, vs: vs.Clone(), block: block, size: size})
+}
+
+// putCheckpoint keeps a copy of the state on the way to the state with index target if it is a checkpoint.
+// Checkpoints too far below the target to stay in the cache with the following ones are not copied
+func (c *historyCache) putCheckpoint(chainID *coretypes.ChainID, vs VirtualState, block Block, target uint32) {
+	if c == nil {
+		return
+	}
+	index := vs.BlockIndex()
+	if index%c.checkpointInterval != 0 {
+		return
+	}
+	// the following checkpoints are not smaller than this one
+	size := stateSize(vs)
+	if uint64(target-index)/uint64(c.checkpointInterval)*uint64(size) > uint64(c.checkpoints.maxBytes) {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.checkpoints.put(&pastState{chainID: *chainID, vs: vs.Clone(), block: block, size: size})
+}
+
+// nearest returns the state of the chain with the greatest index not above stateIndex and marks it as used
+func (l *pastStateList) nearest(chainID *coretypes.ChainID, stateIndex uint32) *pastState {
+	found := -1
+	for i, e := range l.entries {
+		if e.chainID != *chainID || e.vs.BlockIndex() > stateIndex {
+			continue
+		}
+		if found < 0 || e.vs.BlockIndex() > l.entries[found].vs.BlockIndex() {
+			found = i
+		}
+	}
+	if found < 0 {
+		return nil
+	}
+	ret := l.entries[found]
+	l.entries = append(append(l.entries[:found], l.entries[found+1:]...), ret)
+	return ret
+}
+
+// put adds the state as the most recently used one, replacing the same state if present,
+// and evicts the least recently used ones until the list fits in its size. States larger than
+// the whole list are not kept
+func (l *pastStateList) put(ps *pastState) {
+	if ps.size > l.maxBytes {
+		return
+	}
+	for i, e := range l.entries {
+		if e.chainID == ps.chainID && e.vs.BlockIndex() == ps.vs.BlockIndex() {
+			l.entries = append(l.entries[:i], l.entries[i+1:]...)
+			l.bytes -= e.size
+			break
+		}
+	}
+	for len(l.entries) > 0 && l.bytes+ps.size > l.maxBytes {
+		l.bytes -= l.entries[0].size
+		l.entries = l.entries[1:]
+	}
+	l.entries = append(l.entries, ps)
+	l.bytes += ps.size
+}
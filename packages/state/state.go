@@ -2,6 +2,7 @@ package state
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"github.com/iotaledger/wasp/packages/dbprovider"
 	"io"
@@ -173,11 +174,11 @@ func (vs *virtualState) CommitToDb(b Block) error {
 	keys := [][]byte{varStateDbkey, batchDbKey, solidStateKey}
 	values := [][]byte{varStateData, batchData, solidStateValue}
 
-	// store processed request IDs
+	// store processed request IDs with the index of their block
 	// TODO store request IDs in the 'log' contract
 	for _, rid := range b.RequestIDs() {
 		keys = append(keys, dbkeyRequest(rid))
-		values = append(values, []byte{0})
+		values = append(values, util.Uint32To4Bytes(b.StateIndex()))
 	}
 
 	// store uncommitted mutations
@@ -240,6 +241,53 @@ func dbkeyRequest(reqid *coretypes.RequestID) []byte {
 	return dbprovider.MakeKey(dbprovider.ObjectTypeProcessedRequestId, reqid[:])
 }
 
+// ErrRequestBlockUnknown is returned for requests which were recorded as processed without the index of their block
+var ErrRequestBlockUnknown = errors.New("block of the request is not recorded")
+
+// LoadRequestStateUpdate returns the block which contains the processed request and the state update made by it.
+// It returns nils if the request is not processed. The block is recorded only for requests processed by nodes of
+// this version or newer, for the older ones ErrRequestBlockUnknown is returned
+func LoadRequestStateUpdate(chainID *coretypes.ChainID, reqid *coretypes.RequestID) (Block, StateUpdate, error) {
+	return loadRequestStateUpdate(getSCPartition(chainID), reqid)
+}
+
+func loadRequestStateUpdate(db kvstore.KVStore, reqid *coretypes.RequestID) (Block, StateUpdate, error) {
+	stateIndexBin, err := db.Get(dbkeyRequest(reqid))
+	if err == kvstore.ErrKeyNotFound {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(stateIndexBin) != 4 {
+		return nil, nil, ErrRequestBlockUnknown
+	}
+	stateIndex := util.MustUint32From4Bytes(stateIndexBin)
+	data, err := db.Get(dbkeyBatch(stateIndex))
+	if err == kvstore.ErrKeyNotFound {
+		return nil, nil, fmt.Errorf("%w: block #%d of request %s", ErrStateIndexPruned, stateIndex, reqid.String())
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	block, err := NewBlockFromBytes(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading block #%d: %v", stateIndex, err)
+	}
+	var stateUpdate StateUpdate
+	block.ForEach(func(_ uint16, su StateUpdate) bool {
+		if *su.RequestID() == *reqid {
+			stateUpdate = su
+			return false
+		}
+		return true
+	})
+	if stateUpdate == nil {
+		return nil, nil, fmt.Errorf("inconsistent db: request %s is not in block #%d", reqid.String(), stateIndex)
+	}
+	return block, stateUpdate, nil
+}
+
 func IsRequestCompleted(addr *coretypes.ChainID, reqid *coretypes.RequestID) (bool, error) {
 	return getSCPartition(addr).Has(dbkeyRequest(reqid))
 }
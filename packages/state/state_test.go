@@ -1,7 +1,11 @@
 package state
 
 import (
+	"errors"
+	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/goshimmer/packages/database"
@@ -228,3 +232,275 @@ func TestCommit(t *testing.T) {
 	v, _ = partition.Get(dbkeyStateVariable(kv.Key([]byte("x"))))
 	assert.Nil(t, v)
 }
+
+func TestLoadRequestStateUpdate(t *testing.T) {
+	tmpdb, _ := database.NewMemDB()
+	partition := tmpdb.NewStore().WithRealm([]byte("2"))
+	chainID := coretypes.ChainID{1, 3, 3, 7}
+
+	vs := NewVirtualState(partition, &chainID)
+	reqids := make([]coretypes.RequestID, 4)
+	for i := range reqids {
+		txid := (transaction.ID)(hashing.HashStrings(fmt.Sprintf("test string %d", i)))
+		reqids[i] = coretypes.NewRequestID(txid, 0)
+	}
+	// two blocks with two requests each
+	for i := 0; i < 2; i++ {
+		sus := make([]StateUpdate, 2)
+		for j := range sus {
+			sus[j] = NewStateUpdate(&reqids[2*i+j])
+			sus[j].Mutations().Add(buffered.NewMutationSet("x", codec.EncodeInt64(int64(2*i+j))))
+		}
+		block, err := NewBlock(sus)
+		assert.NoError(t, err)
+		block.WithBlockIndex(uint32(i))
+		assert.NoError(t, vs.ApplyBlock(block))
+		assert.NoError(t, vs.CommitToDb(block))
+	}
+
+	for i := range reqids {
+		block, su, err := loadRequestStateUpdate(partition, &reqids[i])
+		assert.NoError(t, err)
+		assert.EqualValues(t, i/2, block.StateIndex())
+		assert.EqualValues(t, reqids[i], *su.RequestID())
+		x, _, _ := codec.DecodeInt64(su.Mutations().Latest("x").Value())
+		assert.EqualValues(t, i, x)
+	}
+
+	unknown := coretypes.NewRequestID((transaction.ID)(hashing.HashStrings("unknown")), 0)
+	block, su, err := loadRequestStateUpdate(partition, &unknown)
+	assert.NoError(t, err)
+	assert.Nil(t, block)
+	assert.Nil(t, su)
+
+	// records of requests processed before the block index was recorded
+	assert.NoError(t, partition.Set(dbkeyRequest(&reqids[0]), []byte{0}))
+	_, _, err = loadRequestStateUpdate(partition, &reqids[0])
+	assert.True(t, errors.Is(err, ErrRequestBlockUnknown))
+
+	assert.NoError(t, partition.Delete(dbkeyBatch(1)))
+	_, _, err = loadRequestStateUpdate(partition, &reqids[2])
+	assert.True(t, errors.Is(err, ErrStateIndexPruned))
+}
+
+func TestLoadStateAtIndex(t *testing.T) {
+	tmpdb, _ := database.NewMemDB()
+	partition := tmpdb.NewStore().WithRealm([]byte("2"))
+	chainID := coretypes.ChainID{1, 3, 3, 7}
+
+	vs := NewVirtualState(partition, &chainID)
+	hashes := make([]hashing.HashValue, 3)
+	for i := range hashes {
+		txid := (transaction.ID)(hashing.HashStrings(fmt.Sprintf("test string %d", i)))
+		reqid := coretypes.NewRequestID(txid, 0)
+		su := NewStateUpdate(&reqid)
+		su.Mutations().Add(buffered.NewMutationSet("x", codec.EncodeInt64(int64(i))))
+		block, err := NewBlock([]StateUpdate{su})
+		assert.NoError(t, err)
+		block.WithBlockIndex(uint32(i))
+		assert.NoError(t, vs.ApplyBlock(block))
+		assert.NoError(t, vs.CommitToDb(block))
+		hashes[i] = vs.Hash()
+	}
+
+	for i := range hashes {
+		past, block, err := loadStateAtIndex(partition, nil, &chainID, uint32(i), historyMaxReplayedBlocks)
+		assert.NoError(t, err)
+		assert.EqualValues(t, i, past.BlockIndex())
+		assert.EqualValues(t, i, block.StateIndex())
+		assert.EqualValues(t, hashes[i], past.Hash())
+		x, _, _ := codec.DecodeInt64(past.Variables().MustGet("x"))
+		assert.EqualValues(t, i, x)
+	}
+
+	_, _, err := loadStateAtIndex(partition, nil, &chainID, 3, historyMaxReplayedBlocks)
+	assert.True(t, errors.Is(err, ErrStateIndexNotReached))
+
+	assert.NoError(t, partition.Delete(dbkeyBatch(0)))
+	_, _, err = loadStateAtIndex(partition, nil, &chainID, 1, historyMaxReplayedBlocks)
+	assert.True(t, errors.Is(err, ErrStateIndexPruned))
+}
+
+func TestLoadStateAtIndexCached(t *testing.T) {
+	tmpdb, _ := database.NewMemDB()
+	partition := tmpdb.NewStore().WithRealm([]byte("2"))
+	chainID := coretypes.ChainID{1, 3, 3, 7}
+
+	vs := NewVirtualState(partition, &chainID)
+	hashes := make([]hashing.HashValue, 10)
+	for i := range hashes {
+		txid := (transaction.ID)(hashing.HashStrings(fmt.Sprintf("test string %d", i)))
+		reqid := coretypes.NewRequestID(txid, 0)
+		su := NewStateUpdate(&reqid)
+		su.Mutations().Add(buffered.NewMutationSet("x", codec.EncodeInt64(int64(i))))
+		block, err := NewBlock([]StateUpdate{su})
+		assert.NoError(t, err)
+		block.WithBlockIndex(uint32(i))
+		assert.NoError(t, vs.ApplyBlock(block))
+		assert.NoError(t, vs.CommitToDb(block))
+		hashes[i] = vs.Hash()
+	}
+
+	// checkpoints every 3 states. Each block adds 9 bytes to the state, so the last 2 checkpoints
+	// and the last reconstructed state are kept
+	cache := newHistoryCache(3, 100, 100)
+	past, _, err := loadStateAtIndex(partition, cache, &chainID, 8, historyMaxReplayedBlocks)
+	assert.NoError(t, err)
+	assert.EqualValues(t, hashes[8], past.Hash())
+
+	// the states from checkpoint #3 on don't need the blocks before it
+	for i := uint32(0); i <= 3; i++ {
+		assert.NoError(t, partition.Delete(dbkeyBatch(i)))
+	}
+	for _, i := range []uint32{8, 7, 6, 4, 3} {
+		past, block, err := loadStateAtIndex(partition, cache, &chainID, i, historyMaxReplayedBlocks)
+		assert.NoError(t, err)
+		assert.EqualValues(t, i, past.BlockIndex())
+		assert.EqualValues(t, i, block.StateIndex())
+		assert.EqualValues(t, hashes[i], past.Hash())
+		x, _, _ := codec.DecodeInt64(past.Variables().MustGet("x"))
+		assert.EqualValues(t, i, x)
+	}
+
+	// the returned states are copies
+	past, _, err = loadStateAtIndex(partition, cache, &chainID, 6, historyMaxReplayedBlocks)
+	assert.NoError(t, err)
+	past.Variables().Set("x", codec.EncodeInt64(100))
+	past, _, err = loadStateAtIndex(partition, cache, &chainID, 6, historyMaxReplayedBlocks)
+	assert.NoError(t, err)
+	assert.EqualValues(t, hashes[6], past.Hash())
+	x, _, _ := codec.DecodeInt64(past.Variables().MustGet("x"))
+	assert.EqualValues(t, 6, x)
+
+	_, _, err = loadStateAtIndex(partition, cache, &chainID, 2, historyMaxReplayedBlocks)
+	assert.True(t, errors.Is(err, ErrStateIndexPruned))
+}
+
+func TestLoadStateAtIndexMaxReplayedBlocks(t *testing.T) {
+	tmpdb, _ := database.NewMemDB()
+	partition := tmpdb.NewStore().WithRealm([]byte("2"))
+	chainID := coretypes.ChainID{1, 3, 3, 7}
+
+	vs := NewVirtualState(partition, &chainID)
+	for i := 0; i < 10; i++ {
+		txid := (transaction.ID)(hashing.HashStrings(fmt.Sprintf("test string %d", i)))
+		reqid := coretypes.NewRequestID(txid, 0)
+		su := NewStateUpdate(&reqid)
+		su.Mutations().Add(buffered.NewMutationSet("x", codec.EncodeInt64(int64(i))))
+		block, err := NewBlock([]StateUpdate{su})
+		assert.NoError(t, err)
+		block.WithBlockIndex(uint32(i))
+		assert.NoError(t, vs.ApplyBlock(block))
+		assert.NoError(t, vs.CommitToDb(block))
+	}
+
+	_, _, err := loadStateAtIndex(partition, nil, &chainID, 3, 4)
+	assert.NoError(t, err)
+	_, _, err = loadStateAtIndex(partition, nil, &chainID, 4, 4)
+	assert.True(t, errors.Is(err, ErrStateIndexPruned))
+
+	// the blocks are counted from the nearest kept state
+	cache := newHistoryCache(3, 1000, 1000)
+	_, _, err = loadStateAtIndex(partition, cache, &chainID, 3, 4)
+	assert.NoError(t, err)
+	past, _, err := loadStateAtIndex(partition, cache, &chainID, 7, 4)
+	assert.NoError(t, err)
+	x, _, _ := codec.DecodeInt64(past.Variables().MustGet("x"))
+	assert.EqualValues(t, 7, x)
+	_, _, err = loadStateAtIndex(partition, cache, &chainID, 8, 4)
+	assert.NoError(t, err)
+	// from the checkpoint #0
+	_, _, err = loadStateAtIndex(partition, cache, &chainID, 2, 2)
+	assert.NoError(t, err)
+	_, _, err = loadStateAtIndex(partition, cache, &chainID, 5, 1)
+	assert.True(t, errors.Is(err, ErrStateIndexPruned))
+}
+
+func TestPastStateListMaxBytes(t *testing.T) {
+	chainID := coretypes.ChainID{1, 3, 3, 7}
+	pastStateOfSize := func(index uint32, size int) *pastState {
+		vs := NewVirtualState(mapdb.NewMapDB(), &chainID)
+		vs.ApplyBlockIndex(index)
+		return &pastState{chainID: chainID, vs: vs, size: size}
+	}
+	indices := func(l *pastStateList) []uint32 {
+		ret := make([]uint32, 0)
+		for _, e := range l.entries {
+			ret = append(ret, e.vs.BlockIndex())
+		}
+		return ret
+	}
+
+	l := &pastStateList{maxBytes: 100}
+	l.put(pastStateOfSize(1, 40))
+	l.put(pastStateOfSize(2, 40))
+	assert.Equal(t, []uint32{1, 2}, indices(l))
+	l.put(pastStateOfSize(3, 40))
+	assert.Equal(t, []uint32{2, 3}, indices(l))
+	assert.Equal(t, 80, l.bytes)
+
+	// replacing a state doesn't count it twice
+	l.put(pastStateOfSize(2, 50))
+	assert.Equal(t, []uint32{3, 2}, indices(l))
+	assert.Equal(t, 90, l.bytes)
+
+	// states larger than the list are not kept
+	l.put(pastStateOfSize(4, 101))
+	assert.Equal(t, []uint32{3, 2}, indices(l))
+	l.put(pastStateOfSize(5, 100))
+	assert.Equal(t, []uint32{5}, indices(l))
+	assert.Equal(t, 100, l.bytes)
+}
+
+func TestHistoryCacheSharedReconstruction(t *testing.T) {
+	chainID := coretypes.ChainID{1, 3, 3, 7}
+	cache := newHistoryCache(3, 1000, 1000)
+
+	var calls int32
+	release := make(chan struct{})
+	reconstruct := func() (VirtualState, Block, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		vs := NewVirtualState(mapdb.NewMapDB(), &chainID)
+		vs.Variables().Set("x", []byte{1})
+		return vs, nil, nil
+	}
+
+	const n = 5
+	results := make(chan VirtualState, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			vs, _, err := cache.reconstruct(&chainID, 7, reconstruct)
+			assert.NoError(t, err)
+			results <- vs
+		}()
+	}
+	// wait until all calls wait for the one in progress
+	for {
+		cache.mutex.Lock()
+		r := cache.inProgress[pastStateKey{chainID: chainID, stateIndex: 7}]
+		cache.mutex.Unlock()
+		if r != nil && atomic.LoadInt32(&calls) == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	for i := 0; i < n; i++ {
+		vs := <-results
+		assert.Equal(t, []byte{1}, vs.Variables().MustGet("x"))
+		// each caller gets its own copy
+		vs.Variables().Set("x", []byte{2})
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+	assert.Empty(t, cache.inProgress)
+
+	// a later call reconstructs again
+	release = make(chan struct{})
+	close(release)
+	_, _, err := cache.reconstruct(&chainID, 7, reconstruct)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
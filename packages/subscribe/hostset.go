@@ -0,0 +1,60 @@
+package subscribe
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// HostSet is a list of publisher hosts in the "host:port" format, without duplicates
+type HostSet []string
+
+// NewHostSet validates the hosts and removes duplicates, keeping the order of the first occurrences
+func NewHostSet(hosts ...string) (HostSet, error) {
+	ret := make(HostSet, 0, len(hosts))
+	seen := make(map[string]bool)
+	for _, host := range hosts {
+		if err := validateHost(host); err != nil {
+			return nil, err
+		}
+		if seen[host] {
+			continue
+		}
+		seen[host] = true
+		ret = append(ret, host)
+	}
+	return ret, nil
+}
+
+func validateHost(host string) error {
+	h, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return fmt.Errorf("invalid publisher host '%s': %v", host, err)
+	}
+	if h == "" {
+		return fmt.Errorf("invalid publisher host '%s': missing host", host)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil || p <= 0 || p > 65535 {
+		return fmt.Errorf("invalid publisher host '%s': wrong port '%s'", host, port)
+	}
+	return nil
+}
+
+// CheckQuorum checks that the quorum is between 0 and the number of the hosts
+func (hs HostSet) CheckQuorum(quorum int) error {
+	if quorum < 0 || quorum > len(hs) {
+		return fmt.Errorf("invalid publisher quorum %d of %d hosts", quorum, len(hs))
+	}
+	return nil
+}
+
+// Dial subscribes to the topics on the hosts, see SubscribeMulti
+func (hs HostSet) Dial(topics []string, quorum ...int) (*Subscription, error) {
+	if len(quorum) > 0 {
+		if err := hs.CheckQuorum(quorum[0]); err != nil {
+			return nil, err
+		}
+	}
+	return SubscribeMulti(hs, topics, quorum...)
+}
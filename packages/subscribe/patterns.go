@@ -0,0 +1,63 @@
+package subscribe
+
+import (
+	"strconv"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+)
+
+// Event kinds published by the Wasp node on nanomsg. The kind is always the first word of the message
+const (
+	EventRequestIn          = "request_in"
+	EventRequestOut         = "request_out"
+	EventState              = "state"
+	EventVMMsg              = "vmmsg"
+	EventChainRecord        = "chainrec"
+	EventActiveCommittee    = "active_committee"
+	EventDismissedCommittee = "dismissed_committee"
+)
+
+// RequestInPattern matches the 'request_in' message of the request with the given index in the transaction
+func RequestInPattern(chainID, txID string, index int) []string {
+	return []string{EventRequestIn, chainID, txID, strconv.Itoa(index)}
+}
+
+// RequestOutPattern matches the 'request_out' message of the request with the given index in the transaction
+func RequestOutPattern(chainID, txID string, index int) []string {
+	return []string{EventRequestOut, chainID, txID, strconv.Itoa(index)}
+}
+
+// RequestInPatternByID is RequestInPattern of the request with the given ID
+func RequestInPatternByID(chainID string, reqID coretypes.RequestID) []string {
+	return RequestInPattern(chainID, reqID.TransactionID().String(), int(reqID.Index()))
+}
+
+// RequestOutPatternByID is RequestOutPattern of the request with the given ID
+func RequestOutPatternByID(chainID string, reqID coretypes.RequestID) []string {
+	return RequestOutPattern(chainID, reqID.TransactionID().String(), int(reqID.Index()))
+}
+
+// StatePattern matches any 'state' message of the chain
+func StatePattern(chainID string) []string {
+	return []string{EventState, chainID}
+}
+
+// VMMsgPattern matches any 'vmmsg' message emitted by the contract on the chain
+func VMMsgPattern(chainID, contractHname string) []string {
+	return []string{EventVMMsg, chainID, contractHname}
+}
+
+// ChainRecordPattern matches the 'chainrec' message of the chain
+func ChainRecordPattern(chainID string) []string {
+	return []string{EventChainRecord, chainID}
+}
+
+// ActiveCommitteePattern matches the 'active_committee' message of the chain
+func ActiveCommitteePattern(chainID string) []string {
+	return []string{EventActiveCommittee, chainID}
+}
+
+// DismissedCommitteePattern matches the 'dismissed_committee' message of the chain
+func DismissedCommitteePattern(chainID string) []string {
+	return []string{EventDismissedCommittee, chainID}
+}
@@ -3,6 +3,7 @@ package subscribe
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"go.nanomsg.org/mangos/v3"
@@ -68,6 +69,7 @@ type Subscription struct {
 	Topics       []string
 	HostMessages chan *HostMessage
 	stopReading  chan bool
+	closeOnce    sync.Once
 }
 
 const (
@@ -75,23 +77,29 @@ const (
 	channelLockTimeout = 1 * time.Second
 )
 
+// NewSubscription creates the subscription without connecting to the hosts.
+// Messages are delivered by sending them to HostMessages
+func NewSubscription(hosts []string, topics []string) *Subscription {
+	return &Subscription{
+		Hosts:        hosts,
+		Topics:       topics,
+		HostMessages: make(chan *HostMessage, channelBufferSize),
+		stopReading:  make(chan bool),
+	}
+}
+
 func SubscribeMulti(hosts []string, topics []string, quorum ...int) (*Subscription, error) {
 	if len(hosts) == 0 {
 		return nil, fmt.Errorf("SubscribeMulti: no nanomsg hosts provided")
 	}
 	quorumNodes := len(hosts)
 	if len(quorum) > 0 {
-		if quorum[0] < 0 || quorum[0] >= len(hosts) {
-			panic("invalid quorum value")
+		if quorum[0] < 0 || quorum[0] > len(hosts) {
+			return nil, fmt.Errorf("SubscribeMulti: invalid quorum %d of %d hosts", quorum[0], len(hosts))
 		}
 		quorumNodes = quorum[0]
 	}
-	ret := &Subscription{
-		Hosts:        hosts,
-		Topics:       topics,
-		HostMessages: make(chan *HostMessage, channelBufferSize),
-		stopReading:  make(chan bool),
-	}
+	ret := NewSubscription(hosts, topics)
 	numSubscribed := 0
 	for _, host := range hosts {
 		hostMessages := make(chan []string)
@@ -126,8 +134,31 @@ func (subs *Subscription) WaitForPattern(pattern []string, timeout time.Duration
 	return subs.WaitForPatterns([][]string{pattern}, timeout, quorum...)
 }
 
+// WaitForPatternEvent is like WaitForPattern, but also returns the complete message which first matched the
+// pattern, e.g. to read the variable tail of the message
+func (subs *Subscription) WaitForPatternEvent(pattern []string, timeout time.Duration, quorum ...int) ([]string, bool) {
+	matched, ok := subs.waitForPatterns([][]string{pattern}, timeout, nil, quorum...)
+	if !ok {
+		return nil, false
+	}
+	return matched[0], true
+}
+
 // WaitForPatterns waits until subscription receives all patterns from quorum of hosts
 func (subs *Subscription) WaitForPatterns(patterns [][]string, timeout time.Duration, quorum ...int) bool {
+	_, ok := subs.waitForPatterns(patterns, timeout, nil, quorum...)
+	return ok
+}
+
+// WaitForPatternNotify is like WaitForPattern, but calls onMatch for each host message matching the pattern
+// before the quorum is reached, e.g. to measure the arrival time of the first event
+func (subs *Subscription) WaitForPatternNotify(pattern []string, timeout time.Duration, onMatch func(*HostMessage), quorum ...int) bool {
+	_, ok := subs.waitForPatterns([][]string{pattern}, timeout, onMatch, quorum...)
+	return ok
+}
+
+// waitForPatterns returns the first message matched by each of the patterns
+func (subs *Subscription) waitForPatterns(patterns [][]string, timeout time.Duration, onMatch func(*HostMessage), quorum ...int) ([][]string, bool) {
 	quorumNodes := len(subs.Hosts)
 	if len(quorum) > 0 {
 		if quorum[0] > 0 {
@@ -141,6 +172,7 @@ func (subs *Subscription) WaitForPatterns(patterns [][]string, timeout time.Dura
 	for i := range received {
 		received[i] = make(map[string]bool)
 	}
+	matched := make([][]string, len(patterns))
 	deadline := time.Now().Add(timeout)
 	for {
 		select {
@@ -149,17 +181,26 @@ func (subs *Subscription) WaitForPatterns(patterns [][]string, timeout time.Dura
 				_, ok := received[i][m.Sender]
 				if !ok {
 					if matches(m.Message, patterns[i]) {
+						if onMatch != nil {
+							onMatch(m)
+						}
 						received[i][m.Sender] = true
+						if matched[i] == nil {
+							matched[i] = m.Message
+						}
 					}
 				}
 			}
 			if checkQuorum(received, quorumNodes) {
-				return true
+				return matched, true
 			}
 
+		case <-subs.stopReading:
+			return nil, false
+
 		case <-time.After(100 * time.Millisecond):
 			if time.Now().After(deadline) {
-				return false
+				return nil, false
 			}
 		}
 	}
@@ -174,8 +215,36 @@ func checkQuorum(m []map[string]bool, quorum int) bool {
 	return true
 }
 
+// Drain returns the messages buffered in the subscription, waiting up to timeout for each next message,
+// e.g. to collect the events which follow the one matched by WaitForPattern.
+// Close discards the messages which are not read yet, so Drain must be called before it. On a closed subscription
+// Drain returns the messages which were buffered before closing without waiting
+func (subs *Subscription) Drain(timeout time.Duration) []*HostMessage {
+	var ret []*HostMessage
+	for {
+		select {
+		case m := <-subs.HostMessages:
+			ret = append(ret, m)
+		case <-subs.stopReading:
+			for {
+				select {
+				case m := <-subs.HostMessages:
+					ret = append(ret, m)
+				default:
+					return ret
+				}
+			}
+		case <-time.After(timeout):
+			return ret
+		}
+	}
+}
+
+// Close stops reading from the hosts. It is safe to call it more than once
 func (subs *Subscription) Close() {
-	close(subs.stopReading)
+	subs.closeOnce.Do(func() {
+		close(subs.stopReading)
+	})
 }
 
 func matches(data, pattern []string) bool {
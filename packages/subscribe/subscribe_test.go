@@ -0,0 +1,114 @@
+package subscribe
+
+import (
+	"testing"
+	"time"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSubscription(hosts ...string) *Subscription {
+	return NewSubscription(hosts, []string{"request_out"})
+}
+
+func TestWaitForPatternEvent(t *testing.T) {
+	subs := newTestSubscription("host1", "host2")
+	subs.HostMessages <- &HostMessage{"host1", []string{"state", "chain", "1"}}
+	subs.HostMessages <- &HostMessage{"host1", []string{"request_out", "chain", "tx", "0", "5"}}
+	subs.HostMessages <- &HostMessage{"host2", []string{"request_out", "chain", "tx", "0", "5"}}
+
+	msg, ok := subs.WaitForPatternEvent([]string{"request_out", "chain", "tx"}, time.Second)
+	require.True(t, ok)
+	require.EqualValues(t, []string{"request_out", "chain", "tx", "0", "5"}, msg)
+
+	msg, ok = subs.WaitForPatternEvent([]string{"request_out", "chain", "other"}, 200*time.Millisecond)
+	require.False(t, ok)
+	require.Nil(t, msg)
+}
+
+func TestRequestOutPattern(t *testing.T) {
+	pattern := RequestOutPattern("chain", "tx", 3)
+	require.EqualValues(t, []string{"request_out", "chain", "tx", "3"}, pattern)
+	require.True(t, matches([]string{"request_out", "chain", "tx", "3", "7", "0", "1"}, pattern))
+	require.False(t, matches([]string{"request_out", "chain", "tx", "30", "7", "0", "1"}, pattern))
+}
+
+func TestWaitForPatternNotify(t *testing.T) {
+	subs := newTestSubscription("host1", "host2")
+	subs.HostMessages <- &HostMessage{"host1", []string{"request_out", "chain", "tx", "0"}}
+	subs.HostMessages <- &HostMessage{"host1", []string{"request_out", "chain", "tx", "0"}}
+	subs.HostMessages <- &HostMessage{"host2", []string{"request_out", "chain", "other", "0"}}
+	subs.HostMessages <- &HostMessage{"host2", []string{"request_out", "chain", "tx", "0"}}
+
+	senders := make([]string, 0)
+	ok := subs.WaitForPatternNotify([]string{"request_out", "chain", "tx"}, time.Second, func(m *HostMessage) {
+		senders = append(senders, m.Sender)
+	})
+	require.True(t, ok)
+	require.EqualValues(t, []string{"host1", "host2"}, senders)
+}
+
+func TestClose(t *testing.T) {
+	subs := newTestSubscription("host1")
+	subs.Close()
+	subs.Close()
+
+	start := time.Now()
+	require.False(t, subs.WaitForPattern([]string{"request_out"}, 10*time.Second))
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestDrain(t *testing.T) {
+	subs := newTestSubscription("host1")
+	subs.HostMessages <- &HostMessage{"host1", []string{"request_out", "chain", "tx", "0"}}
+	subs.HostMessages <- &HostMessage{"host1", []string{"vmmsg", "chain", "result"}}
+	require.True(t, subs.WaitForPattern([]string{"request_out", "chain", "tx"}, time.Second))
+
+	msgs := subs.Drain(50 * time.Millisecond)
+	require.Len(t, msgs, 1)
+	require.EqualValues(t, []string{"vmmsg", "chain", "result"}, msgs[0].Message)
+	require.Empty(t, subs.Drain(10*time.Millisecond))
+
+	subs.HostMessages <- &HostMessage{"host1", []string{"vmmsg", "chain", "late"}}
+	subs.Close()
+	start := time.Now()
+	require.Len(t, subs.Drain(10*time.Second), 1)
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestRequestOutPatternByID(t *testing.T) {
+	reqID := coretypes.NewRequestID(valuetransaction.RandomID(), 2)
+	pattern := RequestOutPatternByID("chain", reqID)
+	require.EqualValues(t, RequestOutPattern("chain", reqID.TransactionID().String(), 2), pattern)
+	require.EqualValues(t, RequestInPattern("chain", reqID.TransactionID().String(), 2), RequestInPatternByID("chain", reqID))
+}
+
+func TestHostSet(t *testing.T) {
+	hosts, err := NewHostSet("127.0.0.1:5550", "wasp1:5550", "127.0.0.1:5550", "[::1]:5550")
+	require.NoError(t, err)
+	require.EqualValues(t, HostSet{"127.0.0.1:5550", "wasp1:5550", "[::1]:5550"}, hosts)
+
+	for _, host := range []string{"", "wasp1", "wasp1:", ":5550", "wasp1:port", "wasp1:0", "wasp1:70000", "tcp://wasp1:5550"} {
+		_, err := NewHostSet("wasp2:5550", host)
+		require.Error(t, err, host)
+	}
+}
+
+func TestHostSetQuorum(t *testing.T) {
+	// the quorum is checked against the hosts without the duplicates
+	hosts, err := NewHostSet("a:1", "a:1", "b:1")
+	require.NoError(t, err)
+	for _, quorum := range []int{0, 1, 2} {
+		require.NoError(t, hosts.CheckQuorum(quorum), quorum)
+	}
+	for _, quorum := range []int{-1, 3} {
+		require.Error(t, hosts.CheckQuorum(quorum), quorum)
+		_, err := hosts.Dial([]string{EventState}, quorum)
+		require.Error(t, err, quorum)
+	}
+	// SubscribeMulti fails instead of panicking
+	_, err = SubscribeMulti([]string{"a:1"}, []string{EventState}, 2)
+	require.Error(t, err)
+}
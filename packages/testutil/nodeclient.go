@@ -62,7 +62,7 @@ func (api *utxodbclient) PostAndWaitForConfirmation(tx *transaction.Transaction)
 
 func (api *utxodbclient) WaitForConfirmation(txid transaction.ID) error {
 	for {
-		conf, err := nodeapi.IsConfirmed(api.goshimmerHost, &txid)
+		conf, err := api.IsConfirmed(txid)
 		if err != nil {
 			return err
 		}
@@ -73,3 +73,8 @@ func (api *utxodbclient) WaitForConfirmation(txid transaction.ID) error {
 	}
 	return nil
 }
+
+// IsConfirmed implements level1.ConfirmationClient
+func (api *utxodbclient) IsConfirmed(txid transaction.ID) (bool, error) {
+	return nodeapi.IsConfirmed(api.goshimmerHost, &txid)
+}
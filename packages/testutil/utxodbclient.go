@@ -0,0 +1,54 @@
+package testutil
+
+import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/utxodb"
+)
+
+// UtxodbLevel1Client is a level1.Level1Client backed by an in-memory UTXODB, for tests without a Goshimmer node.
+// Posted transactions are confirmed at once
+type UtxodbLevel1Client struct {
+	UtxoDB *utxodb.UtxoDB
+	// OnPost, if not nil, is called after each transaction is added to the ledger, e.g. to simulate
+	// the nodes reacting to it before PostTransaction returns
+	OnPost func(tx *transaction.Transaction)
+}
+
+// NewUtxodbLevel1Client returns a UtxodbLevel1Client with a new UTXODB
+func NewUtxodbLevel1Client() *UtxodbLevel1Client {
+	return &UtxodbLevel1Client{UtxoDB: utxodb.New()}
+}
+
+func (c *UtxodbLevel1Client) RequestFunds(addr *address.Address) error {
+	_, err := c.UtxoDB.RequestFunds(*addr)
+	return err
+}
+
+func (c *UtxodbLevel1Client) GetConfirmedAccountOutputs(addr *address.Address) (map[transaction.OutputID][]*balance.Balance, error) {
+	return c.UtxoDB.GetAddressOutputs(*addr), nil
+}
+
+func (c *UtxodbLevel1Client) PostTransaction(tx *transaction.Transaction) error {
+	if err := c.UtxoDB.AddTransaction(tx); err != nil {
+		return err
+	}
+	if c.OnPost != nil {
+		c.OnPost(tx)
+	}
+	return nil
+}
+
+func (c *UtxodbLevel1Client) PostAndWaitForConfirmation(tx *transaction.Transaction) error {
+	return c.PostTransaction(tx)
+}
+
+func (c *UtxodbLevel1Client) WaitForConfirmation(transaction.ID) error {
+	return nil
+}
+
+// IsConfirmed implements level1.ConfirmationClient
+func (c *UtxodbLevel1Client) IsConfirmed(txid transaction.ID) (bool, error) {
+	return c.UtxoDB.IsConfirmed(&txid), nil
+}
@@ -0,0 +1,22 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import "context"
+
+// RunWithContext runs f and waits until it returns or the context is done, whichever comes first.
+// In the latter case the error of the context is returned, while f keeps running in the background
+// until it returns. It is used to bound blocking calls which don't accept a context
+func RunWithContext(ctx context.Context, f func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- f()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
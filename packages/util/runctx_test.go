@@ -0,0 +1,29 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunWithContext(t *testing.T) {
+	errTest := errors.New("test")
+	err := RunWithContext(context.Background(), func() error {
+		return errTest
+	})
+	require.Equal(t, errTest, err)
+
+	timeout := 100 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	err = RunWithContext(ctx, func() error {
+		time.Sleep(2 * time.Second)
+		return nil
+	})
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Less(t, int64(time.Since(start)), int64(timeout+500*time.Millisecond))
+}
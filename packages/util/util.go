@@ -6,6 +6,14 @@ import (
 	"github.com/mr-tron/base58"
 )
 
+// ColorToString returns the string form of the color: "IOTA" for the IOTA color, base58 otherwise.
+// The result is parsed back by ColorFromString
+func ColorToString(color balance.Color) string {
+	return color.String()
+}
+
+// ColorFromString parses the color from the string form returned by ColorToString.
+// Base58 encoding of the IOTA color is accepted too
 func ColorFromString(cs string) (ret balance.Color, err error) {
 	if cs == "IOTA" {
 		ret = balance.ColorIOTA
@@ -0,0 +1,29 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColorStringRoundTrip(t *testing.T) {
+	var col balance.Color
+	copy(col[:], []byte("some color 0123456789 0123456789"))
+
+	for _, c := range []balance.Color{balance.ColorIOTA, balance.ColorNew, col} {
+		s := ColorToString(c)
+		back, err := ColorFromString(s)
+		require.NoError(t, err)
+		require.EqualValues(t, c, back)
+	}
+	require.EqualValues(t, "IOTA", ColorToString(balance.ColorIOTA))
+
+	back, err := ColorFromString(base58.Encode(balance.ColorIOTA[:]))
+	require.NoError(t, err)
+	require.EqualValues(t, balance.ColorIOTA, back)
+
+	_, err = ColorFromString("not a color")
+	require.Error(t, err)
+}
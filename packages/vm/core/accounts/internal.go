@@ -18,6 +18,12 @@ const (
 	varStateTotalAssets = "t"
 )
 
+// TotalAssetsStateKey is the key of the map of the total assets on the chain in the state of the chain,
+// e.g. for state queries. The map is keyed by color, the balances are encoded with util.Uint64To8Bytes
+func TotalAssetsStateKey() kv.Key {
+	return kv.Key(Interface.Hname().Bytes()) + varStateTotalAssets
+}
+
 func getAccountsMap(state kv.KVStore) *collections.Map {
 	return collections.NewMap(state, varStateAccounts)
 }
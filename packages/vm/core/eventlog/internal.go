@@ -1,11 +1,85 @@
 package eventlog
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
 	"github.com/iotaledger/wasp/packages/kv/collections"
 )
 
+// requestRecordPrefix starts the record which the VM appends to the log of the target contract for each processed request
+const requestRecordPrefix = "[req] "
+
+// RequestRecord is the record of a processed request in the log of the target contract
+type RequestRecord struct {
+	Contract coretypes.Hname
+	// EntryPoint is nil in the records made before the VM started to record it
+	EntryPoint *coretypes.Hname
+	// Error is empty if the request succeeded
+	Error string
+}
+
 func AppendToLog(state kv.KVStore, ts int64, contract coretypes.Hname, data []byte) {
 	collections.NewTimestampedLog(state, kv.Key(contract.Bytes())).MustAppend(ts, data)
 }
+
+// RequestRecordData is the data of the record of a processed request, e.g. '[req] [0]Ei4d...kzZm 0cc4c2e6: Ok'
+func RequestRecordData(reqID *coretypes.RequestID, entryPoint coretypes.Hname, err error) []byte {
+	result := "Ok"
+	if err != nil {
+		result = err.Error()
+	}
+	return []byte(fmt.Sprintf("%s%s %s: %s", requestRecordPrefix, reqID.String(), entryPoint.String(), result))
+}
+
+// FindRequestRecord finds the record of the request among the mutations of the chain state made by the request.
+// It returns nil if there is none
+func FindRequestRecord(muts buffered.MutationSequence, reqID *coretypes.RequestID) *RequestRecord {
+	prefix := requestRecordPrefix + reqID.String()
+	var ret *RequestRecord
+	// keys of the log records are <eventlog hname><contract hname><log element key>
+	muts.IterateValues(kv.Key(Interface.Hname().Bytes()), func(key kv.Key, value []byte) bool {
+		if len(key) <= 2*coretypes.HnameLength {
+			return true
+		}
+		rec, err := collections.ParseRawLogRecord(value)
+		if err != nil || !strings.HasPrefix(string(rec.Data), prefix) {
+			return true
+		}
+		contract, err := coretypes.NewHnameFromBytes([]byte(key[coretypes.HnameLength : 2*coretypes.HnameLength]))
+		if err != nil {
+			return true
+		}
+		ret = parseRequestRecord(contract, string(rec.Data)[len(prefix):])
+		return ret == nil
+	})
+	return ret
+}
+
+// parseRequestRecord parses the rest of the record after the request ID: ' <entry point>: <result>',
+// or ': <result>' in the records made before the VM started to record the entry point
+func parseRequestRecord(contract coretypes.Hname, s string) *RequestRecord {
+	ret := &RequestRecord{Contract: contract}
+	if strings.HasPrefix(s, " ") {
+		i := strings.Index(s, ": ")
+		if i < 0 {
+			return nil
+		}
+		entryPoint, err := coretypes.HnameFromString(s[1:i])
+		if err != nil {
+			return nil
+		}
+		ret.EntryPoint = &entryPoint
+		s = s[i:]
+	}
+	if !strings.HasPrefix(s, ": ") {
+		return nil
+	}
+	if result := s[len(": "):]; result != "Ok" {
+		ret.Error = result
+	}
+	return ret
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
 )
 
@@ -171,13 +172,9 @@ func (vmctx *VMContext) mustRequestToEventLog(err error) {
 	if err != nil {
 		vmctx.log.Error(err)
 	}
-	e := "Ok"
-	if err != nil {
-		e = err.Error()
-	}
-	msg := fmt.Sprintf("[req] %s: %s", vmctx.reqRef.RequestID().String(), e)
+	msg := eventlog.RequestRecordData(vmctx.reqRef.RequestID(), vmctx.reqRef.RequestSection().EntryPointCode(), err)
 	vmctx.log.Infof("eventlog -> '%s'", msg)
-	vmctx.StoreToEventLog(vmctx.reqHname, []byte(msg))
+	vmctx.StoreToEventLog(vmctx.reqHname, msg)
 }
 
 // mustGetBaseValues only makes sense if chain is already deployed
@@ -0,0 +1,52 @@
+package admapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addChainInfoEndpoint(adm echoswagger.ApiGroup) {
+	example := model.ChainInfo{
+		ChainID:    model.NewChainID(&coretypes.ChainID{1, 2, 3, 4}),
+		HasState:   true,
+		StateIndex: 42,
+	}
+
+	adm.GET(routes.GetChainInfo(":chainID"), handleGetChainInfo).
+		SetSummary("Get the index of the last solid state of the chain").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddResponse(http.StatusOK, "Chain info", example, nil)
+}
+
+func handleGetChainInfo(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	bd, err := registry.GetChainRecord(&chainID)
+	if err != nil {
+		return err
+	}
+	if bd == nil {
+		return httperrors.NotFound(fmt.Sprintf("ChainRecord not found: %s", chainID))
+	}
+	ret := &model.ChainInfo{ChainID: model.NewChainID(&chainID)}
+	virtualState, _, ok, err := state.LoadSolidState(&chainID)
+	if err != nil {
+		return err
+	}
+	if ok {
+		ret.HasState = true
+		ret.StateIndex = virtualState.BlockIndex()
+	}
+	return c.JSON(http.StatusOK, ret)
+}
@@ -0,0 +1,61 @@
+package admapi
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	registry_pkg "github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/registry"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addCommitteeInfoEndpoint(adm echoswagger.ApiGroup) {
+	example := model.CommitteeInfo{
+		ChainID:      model.NewChainID(&coretypes.ChainID{1, 2, 3, 4}),
+		Nodes:        []string{"wasp1:4000", "wasp2:4000", "wasp3:4000", "wasp4:4000"},
+		Quorum:       3,
+		SharedPubKey: base64.StdEncoding.EncodeToString([]byte("key")),
+	}
+
+	adm.GET(routes.GetChainCommitteeInfo(":chainID"), handleGetChainCommitteeInfo).
+		SetSummary("Get the committee nodes, quorum and shared public key of the chain").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddResponse(http.StatusOK, "Committee info", example, nil)
+}
+
+func handleGetChainCommitteeInfo(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	bd, err := registry_pkg.GetChainRecord(&chainID)
+	if err != nil {
+		return err
+	}
+	if bd == nil {
+		return httperrors.NotFound(fmt.Sprintf("ChainRecord not found: %s", chainID))
+	}
+	// the address of the chain is the shared address of the committee
+	chainAddress := (address.Address)(chainID)
+	dkShare, err := registry.DefaultRegistry().LoadDKShare(&chainAddress)
+	if err != nil {
+		return httperrors.NotFound(fmt.Sprintf("DKShare not found for chain %s: %v", chainID, err))
+	}
+	pubKey, err := dkShare.SharedPublic.MarshalBinary()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, &model.CommitteeInfo{
+		ChainID:      model.NewChainID(&chainID),
+		Nodes:        bd.CommitteeNodes,
+		Quorum:       dkShare.T,
+		SharedPubKey: base64.StdEncoding.EncodeToString(pubKey),
+	})
+}
@@ -24,6 +24,8 @@ func AddEndpoints(adm echoswagger.ApiGroup, adminWhitelist []net.IP) {
 	addChainRecordEndpoints(adm)
 	addChainEndpoints(adm)
 	addDKSharesEndpoints(adm)
+	addCommitteeInfoEndpoint(adm)
+	addChainInfoEndpoint(adm)
 }
 
 // allow only if the remote address is private or in whitelist
@@ -4,6 +4,7 @@ import (
 	"net"
 
 	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/webapi/admapi"
 	"github.com/iotaledger/wasp/packages/webapi/blob"
 	"github.com/iotaledger/wasp/packages/webapi/info"
@@ -14,7 +15,7 @@ import (
 
 var log *logger.Logger
 
-func Init(server echoswagger.ApiRoot, adminWhitelist []net.IP) {
+func Init(server echoswagger.ApiRoot, adminWhitelist []net.IP, stateReaders []coretypes.AgentID, requestTrace bool) {
 	log = logger.NewLogger("WebAPI")
 
 	server.SetRequestContentType("application/json")
@@ -23,8 +24,13 @@ func Init(server echoswagger.ApiRoot, adminWhitelist []net.IP) {
 	pub := server.Group("public", "").SetDescription("Public endpoints")
 	blob.AddEndpoints(pub)
 	info.AddEndpoints(pub)
-	request.AddEndpoints(pub)
-	state.AddEndpoints(pub)
+	// the request traces contain the state mutations, so they are not served when the state readers are restricted
+	if requestTrace && len(stateReaders) > 0 {
+		log.Warnf("request tracing is disabled because the state readers are restricted")
+		requestTrace = false
+	}
+	request.AddEndpoints(pub, requestTrace)
+	state.AddEndpoints(pub, stateReaders)
 
 	adm := server.Group("admin", "").SetDescription("Admin endpoints")
 	admapi.AddEndpoints(adm, adminWhitelist)
@@ -26,3 +26,15 @@ func Conflict(message string) *HTTPError {
 func Timeout(message string) *HTTPError {
 	return &HTTPError{Code: http.StatusRequestTimeout, Message: message}
 }
+
+func Unauthorized(message string) *HTTPError {
+	return &HTTPError{Code: http.StatusUnauthorized, Message: message}
+}
+
+func Forbidden(message string) *HTTPError {
+	return &HTTPError{Code: http.StatusForbidden, Message: message}
+}
+
+func NotImplemented(message string) *HTTPError {
+	return &HTTPError{Code: http.StatusNotImplemented, Message: message}
+}
@@ -0,0 +1,15 @@
+package model
+
+// MaxAccountBalancesAgents is the maximum number of agents in an AccountBalancesRequest
+const MaxAccountBalancesAgents = 100
+
+// AccountBalancesRequest is the list of agents whose on-chain balances are requested
+type AccountBalancesRequest struct {
+	AgentIDs Bytes `json:"agentIDs" swagger:"desc(Agent IDs encoded with coretypes.EncodeAgentIDs (base64-encoded), at most 100)"`
+}
+
+// AccountBalancesResponse contains the on-chain balances of each requested agent. Agents without
+// an account have an empty balance
+type AccountBalancesResponse struct {
+	Balances map[AgentID]map[Color]int64 `json:"balances" swagger:"desc(Balances by color (base58-encoded) of each agent (base58-encoded))"`
+}
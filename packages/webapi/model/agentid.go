@@ -0,0 +1,68 @@
+package model
+
+import (
+	"encoding/json"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+)
+
+// AgentID is the base58 representation of coretypes.AgentID
+type AgentID string
+
+func NewAgentID(agentID *coretypes.AgentID) AgentID {
+	return AgentID(agentID.Base58())
+}
+
+func (a AgentID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(a))
+}
+
+func (a *AgentID) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return a.UnmarshalText([]byte(s))
+}
+
+// UnmarshalText allows web frameworks to bind the AgentID from path and query params
+func (a *AgentID) UnmarshalText(b []byte) error {
+	_, err := coretypes.DecodeAgentID(string(b), coretypes.EncodingBase58)
+	*a = AgentID(b)
+	return err
+}
+
+func (a AgentID) AgentID() coretypes.AgentID {
+	agentID, err := coretypes.DecodeAgentID(string(a), coretypes.EncodingBase58)
+	if err != nil {
+		panic(err)
+	}
+	return agentID
+}
+
+// AgentIDList is a list of agent IDs, represented in JSON as an array of base58 strings
+type AgentIDList []AgentID
+
+func NewAgentIDList(agentIDs []coretypes.AgentID) AgentIDList {
+	ret := make(AgentIDList, len(agentIDs))
+	for i := range agentIDs {
+		ret[i] = NewAgentID(&agentIDs[i])
+	}
+	return ret
+}
+
+// MarshalJSON encodes an empty or nil list as an empty array
+func (l AgentIDList) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]AgentID(l))
+}
+
+func (l AgentIDList) AgentIDs() []coretypes.AgentID {
+	ret := make([]coretypes.AgentID, len(l))
+	for i, a := range l {
+		ret[i] = a.AgentID()
+	}
+	return ret
+}
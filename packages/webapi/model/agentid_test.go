@@ -0,0 +1,90 @@
+package model
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+type agentIDRequest struct {
+	Param AgentID `param:"agentID"`
+	Query AgentID `query:"agent"`
+	Body  AgentID `json:"body"`
+}
+
+func bindAgentIDRequest(t *testing.T, path string, body string) (*agentIDRequest, int) {
+	e := echo.New()
+	var bound *agentIDRequest
+	e.POST("/agent/:agentID", func(c echo.Context) error {
+		var req agentIDRequest
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		bound = &req
+		return c.NoContent(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return bound, rec.Code
+}
+
+func TestAgentIDBinding(t *testing.T) {
+	agentID := coretypes.NewRandomAgentID()
+	s := NewAgentID(&agentID)
+
+	body, err := json.Marshal(map[string]AgentID{"body": s})
+	require.NoError(t, err)
+
+	bound, code := bindAgentIDRequest(t, "/agent/"+string(s)+"?agent="+string(s), string(body))
+	require.Equal(t, http.StatusOK, code)
+	require.EqualValues(t, agentID, bound.Param.AgentID())
+	require.EqualValues(t, agentID, bound.Query.AgentID())
+	require.EqualValues(t, agentID, bound.Body.AgentID())
+
+	_, code = bindAgentIDRequest(t, "/agent/wrong", string(body))
+	require.Equal(t, http.StatusBadRequest, code)
+
+	_, code = bindAgentIDRequest(t, "/agent/"+string(s), `{"body":"wrong"}`)
+	require.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestAgentIDListJSON(t *testing.T) {
+	agentIDs := []coretypes.AgentID{
+		coretypes.NewAgentIDFromAddress(address.Random()),
+		coretypes.NewRandomAgentID(),
+	}
+	e := echo.New()
+	e.GET("/agents", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, NewAgentIDList(agentIDs))
+	})
+	e.GET("/noagents", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, AgentIDList(nil))
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/agents", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var strs []string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &strs))
+	require.Equal(t, []string{agentIDs[0].Base58(), agentIDs[1].Base58()}, strs)
+
+	var list AgentIDList
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.EqualValues(t, agentIDs, list.AgentIDs())
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/noagents", nil))
+	require.JSONEq(t, "[]", rec.Body.String())
+
+	require.Error(t, json.Unmarshal([]byte(`["wrong"]`), &list))
+}
@@ -22,8 +22,13 @@ func (ch *ChainID) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &s); err != nil {
 		return err
 	}
-	_, err := coretypes.NewChainIDFromBase58(s)
-	*ch = ChainID(s)
+	return ch.UnmarshalText([]byte(s))
+}
+
+// UnmarshalText allows web frameworks to bind the ChainID from path and query params
+func (ch *ChainID) UnmarshalText(b []byte) error {
+	_, err := coretypes.NewChainIDFromBase58(string(b))
+	*ch = ChainID(b)
 	return err
 }
 
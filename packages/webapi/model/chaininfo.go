@@ -0,0 +1,8 @@
+package model
+
+// ChainInfo is the current status of the chain on the node
+type ChainInfo struct {
+	ChainID    ChainID `json:"chainID" swagger:"desc(ChainID (base58-encoded))"`
+	HasState   bool    `json:"hasState" swagger:"desc(Whether the node has the state of the chain)"`
+	StateIndex uint32  `json:"stateIndex" swagger:"desc(Index of the last solid state. 0 if HasState is false)"`
+}
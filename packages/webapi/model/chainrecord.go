@@ -1,6 +1,8 @@
 package model
 
 import (
+	"encoding/json"
+
 	"github.com/iotaledger/wasp/packages/registry"
 )
 
@@ -9,22 +11,55 @@ type ChainRecord struct {
 	Color          Color    `swagger:"desc(Chain color (base58-encoded))"`
 	CommitteeNodes []string `swagger:"desc(List of committee nodes (network IDs))"`
 	Active         bool     `swagger:"desc(Whether or not the chain is active)"`
+	// CommitteeOwners is optional
+	CommitteeOwners []Address `json:",omitempty" swagger:"desc(Addresses of the operators of the committee nodes (base58-encoded))"`
+	// CommitteeWeights and WeightedQuorum are optional
+	CommitteeWeights []uint32 `json:",omitempty" swagger:"desc(Weights of the committee nodes)"`
+	WeightedQuorum   uint64   `json:",omitempty" swagger:"desc(Total weight of the committee nodes required for the quorum)"`
 }
 
 func NewChainRecord(bd *registry.ChainRecord) *ChainRecord {
-	return &ChainRecord{
+	ret := &ChainRecord{
 		ChainID:        NewChainID(&bd.ChainID),
 		Color:          NewColor(&bd.Color),
 		CommitteeNodes: bd.CommitteeNodes[:],
 		Active:         bd.Active,
+
+		CommitteeWeights: bd.CommitteeWeights,
+		WeightedQuorum:   bd.WeightedQuorum,
+	}
+	for i := range bd.CommitteeOwners {
+		ret.CommitteeOwners = append(ret.CommitteeOwners, NewAddress(&bd.CommitteeOwners[i]))
 	}
+	return ret
 }
 
 func (bd *ChainRecord) ChainRecord() *registry.ChainRecord {
-	return &registry.ChainRecord{
+	ret := &registry.ChainRecord{
 		ChainID:        bd.ChainID.ChainID(),
 		Color:          bd.Color.Color(),
 		CommitteeNodes: bd.CommitteeNodes[:],
 		Active:         bd.Active,
+
+		CommitteeWeights: bd.CommitteeWeights,
+		WeightedQuorum:   bd.WeightedQuorum,
+	}
+	for _, owner := range bd.CommitteeOwners {
+		ret.CommitteeOwners = append(ret.CommitteeOwners, owner.Address())
+	}
+	return ret
+}
+
+// ChainRecordToJSON marshals the chain record in the same format as it is sent over HTTP
+func ChainRecordToJSON(bd *registry.ChainRecord) ([]byte, error) {
+	return json.Marshal(NewChainRecord(bd))
+}
+
+// ChainRecordFromJSON unmarshals the chain record marshaled by ChainRecordToJSON
+func ChainRecordFromJSON(data []byte) (*registry.ChainRecord, error) {
+	var ret ChainRecord
+	if err := json.Unmarshal(data, &ret); err != nil {
+		return nil, err
 	}
+	return ret.ChainRecord(), nil
 }
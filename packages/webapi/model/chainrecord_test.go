@@ -0,0 +1,38 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainRecordJSON(t *testing.T) {
+	rec := &registry.ChainRecord{
+		ChainID:        coretypes.NewRandomChainID(),
+		Color:          balance.Color{1, 2, 3},
+		CommitteeNodes: []string{"wasp1:4000", "wasp2:4000", "wasp3:4000"},
+		Active:         true,
+	}
+	data, err := ChainRecordToJSON(rec)
+	require.NoError(t, err)
+
+	// the wire format of /adm/chainrecord uses the field names as keys
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	require.Contains(t, fields, "ChainID")
+	require.Contains(t, fields, "Color")
+	require.Contains(t, fields, "CommitteeNodes")
+	require.Contains(t, fields, "Active")
+	require.NotContains(t, fields, "CommitteeOwners")
+
+	back, err := ChainRecordFromJSON(data)
+	require.NoError(t, err)
+	require.EqualValues(t, rec, back)
+
+	_, err = ChainRecordFromJSON([]byte(`{"ChainID":"wrong"}`))
+	require.Error(t, err)
+}
@@ -11,7 +11,7 @@ import (
 type Color string
 
 func NewColor(color *balance.Color) Color {
-	return Color(color.String())
+	return Color(util.ColorToString(*color))
 }
 
 func (c Color) MarshalJSON() ([]byte, error) {
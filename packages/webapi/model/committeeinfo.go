@@ -0,0 +1,9 @@
+package model
+
+// CommitteeInfo is the public information about the committee of the chain, needed to verify its signatures
+type CommitteeInfo struct {
+	ChainID      ChainID  `json:"chainID" swagger:"desc(ChainID (base58-encoded))"`
+	Nodes        []string `json:"nodes" swagger:"desc(NetIDs of the committee nodes)"`
+	Quorum       uint16   `json:"quorum" swagger:"desc(Number of nodes needed to sign)"`
+	SharedPubKey string   `json:"sharedPubKey" swagger:"desc(Aggregated public key of the committee (base64-encoded))"`
+}
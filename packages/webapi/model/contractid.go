@@ -0,0 +1,41 @@
+package model
+
+import (
+	"encoding/json"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+)
+
+// ContractID is the string representation of coretypes.ContractID: <chainID>::<hname>
+type ContractID string
+
+func NewContractID(contractID *coretypes.ContractID) ContractID {
+	return ContractID(contractID.String())
+}
+
+func (c ContractID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(c))
+}
+
+func (c *ContractID) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return c.UnmarshalText([]byte(s))
+}
+
+// UnmarshalText allows web frameworks to bind the ContractID from path and query params
+func (c *ContractID) UnmarshalText(b []byte) error {
+	_, err := coretypes.NewContractIDFromString(string(b))
+	*c = ContractID(b)
+	return err
+}
+
+func (c ContractID) ContractID() coretypes.ContractID {
+	contractID, err := coretypes.NewContractIDFromString(string(c))
+	if err != nil {
+		panic(err)
+	}
+	return contractID
+}
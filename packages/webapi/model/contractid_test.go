@@ -0,0 +1,33 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContractIDJSON(t *testing.T) {
+	contractID := coretypes.NewContractID(coretypes.NewRandomChainID(), coretypes.Hn("test"))
+	data, err := json.Marshal(NewContractID(&contractID))
+	require.NoError(t, err)
+
+	var back ContractID
+	require.NoError(t, json.Unmarshal(data, &back))
+	require.EqualValues(t, contractID, back.ContractID())
+
+	require.Error(t, json.Unmarshal([]byte(`"wrong"`), &back))
+}
+
+func TestChainIDJSON(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	data, err := json.Marshal(NewChainID(&chainID))
+	require.NoError(t, err)
+
+	var back ChainID
+	require.NoError(t, json.Unmarshal(data, &back))
+	require.EqualValues(t, chainID, back.ChainID())
+
+	require.Error(t, json.Unmarshal([]byte(`"wrong"`), &back))
+}
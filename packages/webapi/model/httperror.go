@@ -1,6 +1,7 @@
 package model
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 )
@@ -27,6 +28,12 @@ func (e *HTTPError) Error() string {
 
 // IsHTTPNotFound returns true if the error is an HTTPError with status code http.StatusNotFound
 func IsHTTPNotFound(e error) bool {
-	er, ok := e.(*HTTPError)
-	return ok && er.StatusCode == http.StatusNotFound
+	var er *HTTPError
+	return errors.As(e, &er) && er.StatusCode == http.StatusNotFound
+}
+
+// IsHTTPConflict returns true if the error is an HTTPError with status code http.StatusConflict
+func IsHTTPConflict(e error) bool {
+	var er *HTTPError
+	return errors.As(e, &er) && er.StatusCode == http.StatusConflict
 }
@@ -0,0 +1,12 @@
+package model
+
+// MapEntry is one line of the newline-delimited JSON stream of the entries of a map in the state of a contract
+type MapEntry struct {
+	Key   Bytes `json:"key" swagger:"desc(Key of the map element (base64-encoded))"`
+	Value Bytes `json:"value" swagger:"desc(Value of the map element (base64-encoded))"`
+}
+
+// MapStreamEnd is the last line of the stream of the entries of a map. A stream without it is incomplete
+type MapStreamEnd struct {
+	Entries uint32 `json:"entries" swagger:"desc(Number of the entries in the stream)"`
+}
@@ -11,3 +11,10 @@ type RequestStatusResponse struct {
 }
 
 const WaitRequestProcessedDefaultTimeout = 30 * time.Second
+
+// RequestInfo is a request to the contract received by the committee and not processed yet
+type RequestInfo struct {
+	RequestID    string    `json:"requestID" swagger:"desc(ID of the request (base58-encoded))"`
+	EntryPoint   string    `json:"entryPoint" swagger:"desc(Hname of the entry point called)"`
+	WhenReceived time.Time `json:"whenReceived" swagger:"desc(When the request was received by the node)"`
+}
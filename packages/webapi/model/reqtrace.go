@@ -0,0 +1,20 @@
+package model
+
+// StateMutation is a change of one key of the chain state made while processing the request
+type StateMutation struct {
+	Key     Bytes `json:"key" swagger:"desc(Key in the chain state (base64-encoded))"`
+	Value   Bytes `json:"value" swagger:"desc(New value (base64-encoded). Empty if the key was deleted)"`
+	Deleted bool  `json:"deleted" swagger:"desc(True if the key was deleted)"`
+}
+
+// RequestTrace is the trace of the processing of the request by the VM, as recorded in the block of the request.
+// It is served only by nodes with request tracing enabled
+type RequestTrace struct {
+	RequestID  string          `json:"requestID" swagger:"desc(ID of the request (base58-encoded))"`
+	BlockIndex uint32          `json:"blockIndex" swagger:"desc(Index of the block which contains the request)"`
+	Contract   string          `json:"contract" swagger:"desc(Hname of the target contract)"`
+	EntryPoint string          `json:"entryPoint" swagger:"desc(Hname of the entry point called. Empty for requests processed before the VM recorded it)"`
+	GasUsed    *uint64         `json:"gasUsed" swagger:"desc(Gas used by the request. Always null: the VM does not account gas)"`
+	Mutations  []StateMutation `json:"mutations" swagger:"desc(Changes of the chain state, in the order they were made)"`
+	Error      string          `json:"error,omitempty" swagger:"desc(Error of the request. Empty if it succeeded)"`
+}
@@ -0,0 +1,85 @@
+package statequery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// SignedRequest is a Request signed by the agent reading the state, so that the node can authorize the read.
+// The request is carried in the exact encoding which was signed, so the signature doesn't depend on how
+// the JSON is encoded by either side
+type SignedRequest struct {
+	Request   []byte // JSON encoding of the Request
+	Timestamp int64  // Unix nanoseconds. Limits the time the signed request can be replayed
+	Signature []byte // signaturescheme.Signature.Bytes() of the SignedRequestEssence
+}
+
+// SignedRequestEssence returns the bytes which are signed: the chain ID, the timestamp (uint64, little endian)
+// and the encoded request. The chain ID prevents the signed request to be replayed on other chains
+func SignedRequestEssence(chainID *coretypes.ChainID, request []byte, timestamp int64) []byte {
+	var buf bytes.Buffer
+	buf.Write(chainID[:])
+	_ = util.WriteUint64(&buf, uint64(timestamp))
+	buf.Write(request)
+	return buf.Bytes()
+}
+
+// Sign signs the request to be sent to the given chain
+func (q *Request) Sign(chainID *coretypes.ChainID, sigScheme signaturescheme.SignatureScheme) (*SignedRequest, error) {
+	data, err := json.Marshal(q)
+	if err != nil {
+		return nil, err
+	}
+	ts := time.Now().UnixNano()
+	return &SignedRequest{
+		Request:   data,
+		Timestamp: ts,
+		Signature: sigScheme.Sign(SignedRequestEssence(chainID, data, ts)).Bytes(),
+	}, nil
+}
+
+// Verify checks the signature and that the request was signed at most maxAge ago.
+// It returns the agent ID of the signer and the decoded request
+func (s *SignedRequest) Verify(chainID *coretypes.ChainID, maxAge time.Duration) (coretypes.AgentID, *Request, error) {
+	if len(s.Request) == 0 {
+		return coretypes.AgentID{}, nil, fmt.Errorf("signed state query: no request")
+	}
+	if age := time.Since(time.Unix(0, s.Timestamp)); age > maxAge || age < -maxAge {
+		return coretypes.AgentID{}, nil, fmt.Errorf("signed state query: timestamp out of range")
+	}
+	sig, err := signatureFromBytes(s.Signature)
+	if err != nil {
+		return coretypes.AgentID{}, nil, err
+	}
+	if !sig.IsValid(SignedRequestEssence(chainID, s.Request, s.Timestamp)) {
+		return coretypes.AgentID{}, nil, fmt.Errorf("signed state query: invalid signature")
+	}
+	req := &Request{}
+	if err := json.Unmarshal(s.Request, req); err != nil {
+		return coretypes.AgentID{}, nil, fmt.Errorf("signed state query: %v", err)
+	}
+	return coretypes.NewAgentIDFromAddress(sig.Address()), req, nil
+}
+
+// signatureFromBytes parses the signature by the version byte of its address
+func signatureFromBytes(data []byte) (signaturescheme.Signature, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("signed state query: no signature")
+	}
+	switch data[0] {
+	case address.VersionED25519:
+		sig, _, err := signaturescheme.Ed25519SignatureFromBytes(data)
+		return sig, err
+	case address.VersionBLS:
+		sig, _, err := signaturescheme.BLSSignatureFromBytes(data)
+		return sig, err
+	}
+	return nil, fmt.Errorf("signed state query: unknown signature version %d", data[0])
+}
@@ -1,9 +1,10 @@
-// +build ignore
-
 package statequery
 
 import (
+	"bytes"
+	"container/heap"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -17,11 +18,22 @@ import (
 	"github.com/iotaledger/wasp/packages/webapi/model"
 )
 
+// Request can contain any number of key queries (AddScalar, AddMap, AddMapElement etc).
+// All of them are executed by the node on the same state in one call
 type Request struct {
 	QueryGeneralData bool
 	KeyQueries       []*KeyQuery
+	// if not nil, the queries are executed on the state with the given index instead of the solid state.
+	// Past states are reconstructed by the node from stored blocks, applying them from the origin of the chain or from
+	// the nearest state the node keeps in memory. The node keeps recently reconstructed states and checkpoints, so
+	// repeated queries of the same state (e.g. the pages of a map) and queries of nearby preceding states apply few
+	// blocks, if any. The query fails as pruned if some of the blocks were pruned, or if more blocks would be
+	// applied than the node allows for one query
+	StateIndex *uint32
 }
 
+// Results contains one result for each key query of the request, in the same order.
+// Results are also keyed by the state key of the query, see Get
 type Results struct {
 	KeyQueryResults []*QueryResult
 	byKey           map[kv.Key]*QueryResult
@@ -66,8 +78,20 @@ type TLogSliceDataQueryParams struct {
 	Descending bool
 }
 
+// ErrZeroPageLimit is returned by a map query with a cursor and Limit == 0: such a page would be empty,
+// with the same cursor to continue from
+var ErrZeroPageLimit = errors.New("map query with a cursor must have a limit > 0")
+
 type MapQueryParams struct {
-	Limit uint32
+	Limit    uint32
+	Cursor   *MapCursor // if not nil, entries are returned sorted by key, starting after the cursor. Limit must be > 0
+	KeysOnly bool       // if true, entries are returned with keys only and nil values
+}
+
+// MapCursor marks the position in a paged map query: it carries the last returned key.
+// The zero value (nil LastKey) starts from the beginning of the map
+type MapCursor struct {
+	LastKey []byte
 }
 
 type MapElementQueryParams struct {
@@ -93,6 +117,7 @@ type KeyValuePair struct {
 type MapResult struct {
 	Len     uint32
 	Entries []KeyValuePair
+	Next    *MapCursor // returned only for paged queries, nil if no more entries remain
 }
 
 type MapElementResult struct {
@@ -120,6 +145,12 @@ func NewRequest() *Request {
 	return &Request{}
 }
 
+// AtStateIndex makes the request query the state at the given state index (see Request.StateIndex)
+func (q *Request) AtStateIndex(idx uint32) *Request {
+	q.StateIndex = &idx
+	return q
+}
+
 func (q *Request) AddGeneralData() {
 	q.QueryGeneralData = true
 }
@@ -152,6 +183,29 @@ func (q *Request) AddMap(key kv.Key, limit uint32) {
 	})
 }
 
+// AddMapFrom requests at most limit entries of the map, in the order of keys, starting after the cursor.
+// The next cursor is returned in MapResult.Next. The limit must be > 0, see ErrZeroPageLimit
+func (q *Request) AddMapFrom(key kv.Key, cursor MapCursor, limit uint32) {
+	p := &MapQueryParams{Limit: limit, Cursor: &cursor}
+	params, _ := json.Marshal(p)
+	q.KeyQueries = append(q.KeyQueries, &KeyQuery{
+		Key:    []byte(key),
+		Type:   ValueTypeMap,
+		Params: json.RawMessage(params),
+	})
+}
+
+// AddMapKeys is like AddMapFrom, but the entries of the result contain only keys, without values
+func (q *Request) AddMapKeys(key kv.Key, cursor MapCursor, limit uint32) {
+	p := &MapQueryParams{Limit: limit, Cursor: &cursor, KeysOnly: true}
+	params, _ := json.Marshal(p)
+	q.KeyQueries = append(q.KeyQueries, &KeyQuery{
+		Key:    []byte(key),
+		Type:   ValueTypeMap,
+		Params: json.RawMessage(params),
+	})
+}
+
 func (q *Request) AddMapElement(mapKey kv.Key, elemKey []byte) {
 	p := &MapElementQueryParams{Key: elemKey}
 	params, _ := json.Marshal(p)
@@ -275,16 +329,36 @@ func (r *QueryResult) MustTLogSliceDataResult() *TLogSliceDataResult {
 	return &sr
 }
 
+// Get returns the result of the query of the state key, nil if there is none.
+// If the request had several queries of the same key (e.g. a map and an element of the map),
+// Get returns the result of the last one; the others are found in KeyQueryResults by the index of the query
 func (r *Results) Get(key kv.Key) *QueryResult {
 	if r.byKey == nil {
 		r.byKey = make(map[kv.Key]*QueryResult)
 		for _, qr := range r.KeyQueryResults {
+			if qr == nil {
+				// the queried key or element doesn't exist
+				continue
+			}
 			r.byKey[kv.Key(qr.Key)] = qr
 		}
 	}
 	return r.byKey[key]
 }
 
+// Execute executes all key queries of the request on the state. The results are in the order of the queries
+func (q *Request) Execute(vars buffered.BufferedKVStore) ([]*QueryResult, error) {
+	ret := make([]*QueryResult, len(q.KeyQueries))
+	for i, kq := range q.KeyQueries {
+		result, err := kq.Execute(vars)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = result
+	}
+	return ret, nil
+}
+
 func (q *KeyQuery) Execute(vars buffered.BufferedKVStore) (*QueryResult, error) {
 	key := kv.Key(q.Key)
 	switch q.Type {
@@ -327,11 +401,22 @@ func (q *KeyQuery) Execute(vars buffered.BufferedKVStore) (*QueryResult, error)
 
 		m := collections.NewMap(vars, string(key))
 
+		if params.Cursor != nil {
+			return q.executeMapPage(m, &params)
+		}
+
 		entries := make([]KeyValuePair, 0)
-		err = m.Iterate(func(elemKey []byte, value []byte) bool {
-			entries = append(entries, KeyValuePair{Key: elemKey, Value: value})
-			return len(entries) < int(params.Limit)
-		})
+		if params.KeysOnly {
+			err = m.IterateKeys(func(elemKey []byte) bool {
+				entries = append(entries, KeyValuePair{Key: elemKey})
+				return len(entries) < int(params.Limit)
+			})
+		} else {
+			err = m.Iterate(func(elemKey []byte, value []byte) bool {
+				entries = append(entries, KeyValuePair{Key: elemKey, Value: value})
+				return len(entries) < int(params.Limit)
+			})
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -403,6 +488,70 @@ func (q *KeyQuery) Execute(vars buffered.BufferedKVStore) (*QueryResult, error)
 	return nil, fmt.Errorf("No handler for type %s", q.Type)
 }
 
+// keyMaxHeap is a max-heap of keys, used to keep the smallest keys of a map
+type keyMaxHeap [][]byte
+
+func (h keyMaxHeap) Len() int            { return len(h) }
+func (h keyMaxHeap) Less(i, j int) bool  { return bytes.Compare(h[i], h[j]) > 0 }
+func (h keyMaxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *keyMaxHeap) Push(x interface{}) { *h = append(*h, x.([]byte)) }
+func (h *keyMaxHeap) Pop() interface{} {
+	old := *h
+	ret := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return ret
+}
+
+// executeMapPage returns the entries following the cursor, sorted by key. Only the Limit+1 smallest keys after
+// the cursor are kept while iterating the map: the extra one tells if there are more entries after the page
+func (q *KeyQuery) executeMapPage(m *collections.Map, params *MapQueryParams) (*QueryResult, error) {
+	if params.Limit == 0 {
+		return nil, ErrZeroPageLimit
+	}
+	keep := int(params.Limit) + 1
+	h := &keyMaxHeap{}
+	err := m.IterateKeys(func(elemKey []byte) bool {
+		if params.Cursor.LastKey != nil && bytes.Compare(elemKey, params.Cursor.LastKey) <= 0 {
+			return true
+		}
+		if h.Len() < keep {
+			heap.Push(h, elemKey)
+		} else if bytes.Compare(elemKey, (*h)[0]) < 0 {
+			(*h)[0] = elemKey
+			heap.Fix(h, 0)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	more := h.Len() > int(params.Limit)
+	if more {
+		heap.Pop(h)
+	}
+	keys := make([][]byte, h.Len())
+	for i := len(keys) - 1; i >= 0; i-- {
+		keys[i] = heap.Pop(h).([]byte)
+	}
+	ret := MapResult{Entries: make([]KeyValuePair, 0, len(keys))}
+	for _, k := range keys {
+		var v []byte
+		if !params.KeysOnly {
+			if v, err = m.GetAt(k); err != nil {
+				return nil, err
+			}
+		}
+		ret.Entries = append(ret.Entries, KeyValuePair{Key: k, Value: v})
+	}
+	if more {
+		ret.Next = &MapCursor{LastKey: keys[len(keys)-1]}
+	}
+	if ret.Len, err = m.Len(); err != nil {
+		return nil, err
+	}
+	return q.makeResult(ret)
+}
+
 func (q *KeyQuery) makeResult(value interface{}) (*QueryResult, error) {
 	b, err := json.Marshal(value)
 	if err != nil {
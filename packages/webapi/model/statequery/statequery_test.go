@@ -0,0 +1,146 @@
+package statequery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapKeysOnly(t *testing.T) {
+	vars := buffered.NewBufferedKVStore(mapdb.NewMapDB())
+	m := collections.NewMap(vars, "m")
+	m.MustSetAt([]byte("a"), []byte("value a"))
+	m.MustSetAt([]byte("b"), []byte("value b"))
+	m.MustSetAt([]byte("c"), []byte("value c"))
+
+	req := NewRequest()
+	req.AddMapKeys("m", MapCursor{}, 2)
+	res, err := req.KeyQueries[0].Execute(vars)
+	require.NoError(t, err)
+	results := &Results{KeyQueryResults: []*QueryResult{res}}
+	page := results.Get("m").MustMapResult()
+	require.EqualValues(t, 3, page.Len)
+	require.Len(t, page.Entries, 2)
+	for i, k := range []string{"a", "b"} {
+		require.EqualValues(t, k, string(page.Entries[i].Key))
+		require.Nil(t, page.Entries[i].Value)
+	}
+	require.NotNil(t, page.Next)
+
+	req = NewRequest()
+	req.AddMapFrom("m", MapCursor{}, 2)
+	res, err = req.KeyQueries[0].Execute(vars)
+	require.NoError(t, err)
+	results = &Results{KeyQueryResults: []*QueryResult{res}}
+	require.EqualValues(t, "value a", string(results.Get("m").MustMapResult().Entries[0].Value))
+
+	// a page without entries would never advance the cursor
+	req = NewRequest()
+	req.AddMapFrom("m", MapCursor{}, 0)
+	_, err = req.Execute(vars)
+	require.True(t, errors.Is(err, ErrZeroPageLimit))
+}
+
+func TestMapPages(t *testing.T) {
+	vars := buffered.NewBufferedKVStore(mapdb.NewMapDB())
+	m := collections.NewMap(vars, "m")
+	const n = 20
+	for _, i := range rand.Perm(n) {
+		m.MustSetAt([]byte(fmt.Sprintf("k%02d", i)), []byte(fmt.Sprintf("v%02d", i)))
+	}
+
+	for limit := uint32(1); limit <= n+1; limit++ {
+		keys := make([]string, 0)
+		cursor := MapCursor{}
+		for {
+			req := NewRequest()
+			req.AddMapFrom("m", cursor, limit)
+			res, err := req.KeyQueries[0].Execute(vars)
+			require.NoError(t, err)
+			page := (&Results{KeyQueryResults: []*QueryResult{res}}).Get("m").MustMapResult()
+			require.EqualValues(t, n, page.Len)
+			require.LessOrEqual(t, len(page.Entries), int(limit))
+			for _, e := range page.Entries {
+				require.Equal(t, "v"+string(e.Key[1:]), string(e.Value))
+				keys = append(keys, string(e.Key))
+			}
+			if page.Next == nil {
+				break
+			}
+			require.Len(t, page.Entries, int(limit))
+			require.Equal(t, page.Entries[len(page.Entries)-1].Key, page.Next.LastKey)
+			cursor = *page.Next
+		}
+		require.Len(t, keys, n)
+		for i, k := range keys {
+			require.Equal(t, fmt.Sprintf("k%02d", i), k)
+		}
+	}
+}
+
+func TestRequestManyQueries(t *testing.T) {
+	vars := buffered.NewBufferedKVStore(mapdb.NewMapDB())
+	vars.Set("s", []byte("scalar"))
+	m := collections.NewMap(vars, "m")
+	m.MustSetAt([]byte("a"), []byte("value a"))
+	m.MustSetAt([]byte("b"), []byte("value b"))
+	cfg := collections.NewMap(vars, "cfg")
+	cfg.MustSetAt([]byte("k"), []byte("config"))
+
+	req := NewRequest()
+	req.AddScalar("s")
+	req.AddMap("m", 10)
+	req.AddMapElement("cfg", []byte("k"))
+	req.AddMapElement("m", []byte("b"))
+	res, err := req.Execute(vars)
+	require.NoError(t, err)
+	require.Len(t, res, 4)
+
+	results := &Results{KeyQueryResults: res}
+	require.EqualValues(t, "scalar", string(results.Get("s").MustBytes()))
+	require.EqualValues(t, "config", string(results.Get("cfg").MustMapElementResult()))
+	// two queries of the same key: Get returns the last one
+	require.EqualValues(t, "value b", string(results.Get("m").MustMapElementResult()))
+	require.Len(t, results.KeyQueryResults[1].MustMapResult().Entries, 2)
+}
+
+func TestSignedRequest(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	sigScheme := signaturescheme.RandBLS()
+	req := NewRequest()
+	req.AddScalar("a")
+
+	signed, err := req.Sign(&chainID, sigScheme)
+	require.NoError(t, err)
+	agentID, verified, err := signed.Verify(&chainID, time.Minute)
+	require.NoError(t, err)
+	require.EqualValues(t, coretypes.NewAgentIDFromAddress(sigScheme.Address()), agentID)
+	data, err := json.Marshal(verified)
+	require.NoError(t, err)
+	require.EqualValues(t, signed.Request, data)
+
+	otherChainID := coretypes.NewRandomChainID()
+	_, _, err = signed.Verify(&otherChainID, time.Minute)
+	require.Error(t, err)
+
+	// the signature covers the encoded request, whatever the encoding
+	signed.Request = append(signed.Request, ' ')
+	_, _, err = signed.Verify(&chainID, time.Minute)
+	require.Error(t, err)
+
+	req.AddScalar("b")
+	signed.Request, err = json.Marshal(req)
+	require.NoError(t, err)
+	_, _, err = signed.Verify(&chainID, time.Minute)
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package request
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -16,7 +17,7 @@ import (
 	"github.com/pangpanglabs/echoswagger/v2"
 )
 
-func AddEndpoints(server echoswagger.ApiRouter) {
+func AddEndpoints(server echoswagger.ApiRouter, requestTrace bool) {
 	server.GET(routes.RequestStatus(":chainID", ":reqID"), handleRequestStatus).
 		SetSummary("Get the processing status of a given request in the node").
 		AddParamPath("", "chainID", "ChainID (base58)").
@@ -28,6 +29,21 @@ func AddEndpoints(server echoswagger.ApiRouter) {
 		AddParamPath("", "chainID", "ChainID (base58)").
 		AddParamPath("", "reqID", "Request ID (base58)").
 		AddParamBody(model.WaitRequestProcessedParams{}, "Params", "Optional parameters", false)
+
+	server.GET(routes.PendingRequests(":contractID"), handlePendingRequests).
+		SetSummary("Get the requests to the contract which are in the backlog of the committee, not processed yet").
+		AddParamPath("", "contractID", "ContractID (base58)").
+		AddResponse(http.StatusOK, "Pending requests, in the order they were received", []model.RequestInfo{}, nil).
+		AddResponse(http.StatusConflict, "The node is not in the committee of the chain", httperrors.Conflict("Conflict"), nil)
+
+	server.GET(routes.RequestTrace(":chainID", ":reqID"), requestTraceHandler(requestTrace)).
+		SetSummary("Get the trace of the processing of the request by the VM").
+		SetDescription("Enabled by webapi.requestTrace. Read from the block of the request, so only available while the block is stored").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamPath("", "reqID", "Request ID (base58)").
+		AddResponse(http.StatusOK, "Request trace", model.RequestTrace{}, nil).
+		AddResponse(http.StatusNotFound, "The request is not processed or its block is not available", httperrors.NotFound("Not found"), nil).
+		AddResponse(http.StatusNotImplemented, "Request tracing is not enabled on the node", httperrors.NotImplemented("Not Implemented"), nil)
 }
 
 func handleRequestStatus(c echo.Context) error {
@@ -89,6 +105,36 @@ func handleWaitRequestProcessed(c echo.Context) error {
 	}
 }
 
+func handlePendingRequests(c echo.Context) error {
+	contractID, err := coretypes.NewContractIDFromBase58(c.Param("contractID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid contract ID %+v: %s", c.Param("contractID"), err.Error()))
+	}
+	ch := chains.GetChain(contractID.ChainID())
+	if ch == nil {
+		return httperrors.NotFound(fmt.Sprintf("Chain not found: %+v", contractID.ChainID().String()))
+	}
+	backlog, err := ch.GetBacklog()
+	if errors.Is(err, chain.ErrNotCommitteeNode) {
+		return httperrors.Conflict(fmt.Sprintf("Node is not in the committee of the chain %s", contractID.ChainID().String()))
+	}
+	if err != nil {
+		return err
+	}
+	ret := make([]model.RequestInfo, 0)
+	for _, req := range backlog {
+		if req.Target != contractID {
+			continue
+		}
+		ret = append(ret, model.RequestInfo{
+			RequestID:    req.RequestID.Base58(),
+			EntryPoint:   req.EntryPoint.String(),
+			WhenReceived: req.WhenReceived,
+		})
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
 func parseParams(c echo.Context) (chain.Chain, *coretypes.RequestID, error) {
 	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
 	if err != nil {
@@ -0,0 +1,66 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/labstack/echo/v4"
+)
+
+// requestTraceHandler serves the traces of the processed requests, read from the stored blocks.
+// It responds with 501 Not Implemented if request tracing is not enabled on the node
+func requestTraceHandler(enabled bool) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !enabled {
+			return httperrors.NotImplemented("Request tracing is not enabled on the node")
+		}
+		ch, reqID, err := parseParams(c)
+		if err != nil {
+			return err
+		}
+		block, stateUpdate, err := state.LoadRequestStateUpdate(ch.ID(), reqID)
+		if errors.Is(err, state.ErrRequestBlockUnknown) || errors.Is(err, state.ErrStateIndexPruned) {
+			return httperrors.NotFound(fmt.Sprintf("Trace of request %s is not available: %v", reqID.Base58(), err))
+		}
+		if err != nil {
+			return err
+		}
+		if stateUpdate == nil {
+			return httperrors.NotFound(fmt.Sprintf("Request not processed: %s", reqID.Base58()))
+		}
+		return c.JSON(http.StatusOK, requestTrace(reqID, block.StateIndex(), stateUpdate.Mutations()))
+	}
+}
+
+// requestTrace builds the trace from the mutations made by the request. The contract, entry point and error are
+// taken from the record of the request which the VM appends to the event log of the contract
+func requestTrace(reqID *coretypes.RequestID, blockIndex uint32, muts buffered.MutationSequence) *model.RequestTrace {
+	ret := &model.RequestTrace{
+		RequestID:  reqID.Base58(),
+		BlockIndex: blockIndex,
+		Mutations:  make([]model.StateMutation, 0, muts.Len()),
+	}
+	muts.Iterate(func(mut buffered.Mutation) bool {
+		ret.Mutations = append(ret.Mutations, model.StateMutation{
+			Key:     model.NewBytes([]byte(mut.Key())),
+			Value:   model.NewBytes(mut.Value()),
+			Deleted: mut.Value() == nil,
+		})
+		return true
+	})
+	if rec := eventlog.FindRequestRecord(muts, reqID); rec != nil {
+		ret.Contract = rec.Contract.String()
+		if rec.EntryPoint != nil {
+			ret.EntryPoint = rec.EntryPoint.String()
+		}
+		ret.Error = rec.Error
+	}
+	return ret
+}
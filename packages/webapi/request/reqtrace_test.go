@@ -0,0 +1,72 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// appendToEventLog adds the mutations made by appending the records to the event log of the contract
+func appendToEventLog(muts buffered.MutationSequence, contract coretypes.Hname, records ...[]byte) {
+	log := dict.New()
+	for i, rec := range records {
+		eventlog.AppendToLog(log, int64(i), contract, rec)
+	}
+	_ = log.Iterate("", func(key kv.Key, value []byte) bool {
+		muts.Add(buffered.NewMutationSet(kv.Key(eventlog.Interface.Hname().Bytes())+key, value))
+		return true
+	})
+}
+
+func TestRequestTrace(t *testing.T) {
+	reqID := coretypes.NewRequestID(transaction.ID{1}, 0)
+	otherReqID := coretypes.NewRequestID(transaction.ID{2}, 0)
+	contract := coretypes.Hn("tokenregistry")
+	entryPoint := coretypes.Hn("mintSupply")
+
+	muts := buffered.NewMutationSequence()
+	muts.Add(buffered.NewMutationSet("k", []byte("v")))
+	muts.Add(buffered.NewMutationDel("d"))
+	appendToEventLog(muts, contract,
+		eventlog.RequestRecordData(&otherReqID, entryPoint, nil),
+		eventlog.RequestRecordData(&reqID, entryPoint, errors.New("supply: must be positive")),
+	)
+
+	trace := requestTrace(&reqID, 3, muts)
+	require.Equal(t, reqID.Base58(), trace.RequestID)
+	require.EqualValues(t, 3, trace.BlockIndex)
+	require.Equal(t, contract.String(), trace.Contract)
+	require.Equal(t, entryPoint.String(), trace.EntryPoint)
+	require.Equal(t, "supply: must be positive", trace.Error)
+	require.Nil(t, trace.GasUsed)
+	require.Equal(t, muts.Len(), len(trace.Mutations))
+	require.Equal(t, model.StateMutation{Key: model.NewBytes([]byte("k")), Value: model.NewBytes([]byte("v"))}, trace.Mutations[0])
+	require.Equal(t, model.StateMutation{Key: model.NewBytes([]byte("d")), Value: model.NewBytes(nil), Deleted: true}, trace.Mutations[1])
+
+	// records made before the VM recorded the entry point
+	muts = buffered.NewMutationSequence()
+	appendToEventLog(muts, contract, []byte("[req] "+reqID.String()+": Ok"))
+	trace = requestTrace(&reqID, 3, muts)
+	require.Equal(t, contract.String(), trace.Contract)
+	require.Empty(t, trace.EntryPoint)
+	require.Empty(t, trace.Error)
+}
+
+func TestRequestTraceDisabled(t *testing.T) {
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	he, ok := requestTraceHandler(false)(c).(*httperrors.HTTPError)
+	require.True(t, ok)
+	require.Equal(t, http.StatusNotImplemented, he.Code)
+}
@@ -16,10 +16,30 @@ func WaitRequestProcessed(chainID string, reqID string) string {
 	return "/chain/" + chainID + "/request/" + reqID + "/wait"
 }
 
+func PendingRequests(contractID string) string {
+	return "/contract/" + contractID + "/requests/pending"
+}
+
+func RequestTrace(chainID string, reqID string) string {
+	return "/debug/chain/" + chainID + "/request/" + reqID + "/trace"
+}
+
 func StateQuery(chainID string) string {
 	return "/chain/" + chainID + "/state/query"
 }
 
+func StateQuerySigned(chainID string) string {
+	return "/chain/" + chainID + "/state/query/signed"
+}
+
+func AccountBalances(chainID string) string {
+	return "/chain/" + chainID + "/accounts/balances"
+}
+
+func StreamStateMap(contractID string, mapName string) string {
+	return "/contract/" + contractID + "/state/map/" + mapName + "/stream"
+}
+
 func PutBlob() string {
 	return "/blob/put"
 }
@@ -52,6 +72,14 @@ func GetChainRecord(chainID string) string {
 	return "/adm/chainrecord/" + chainID
 }
 
+func GetChainCommitteeInfo(chainID string) string {
+	return "/adm/chain/" + chainID + "/committeeinfo"
+}
+
+func GetChainInfo(chainID string) string {
+	return "/adm/chain/" + chainID + "/info"
+}
+
 func DKSharesPost() string {
 	return "/adm/dks"
 }
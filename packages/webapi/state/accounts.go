@@ -0,0 +1,87 @@
+package state
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/viewcontext"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/chains"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addAccountBalancesEndpoint(server echoswagger.ApiRouter) {
+	agentID := coretypes.NewRandomAgentID()
+	exampleReq := model.AccountBalancesRequest{
+		AgentIDs: model.NewBytes(coretypes.EncodeAgentIDs([]coretypes.AgentID{agentID})),
+	}
+	exampleRes := model.AccountBalancesResponse{
+		Balances: map[model.AgentID]map[model.Color]int64{
+			model.NewAgentID(&agentID): {model.NewColor(&balance.ColorIOTA): 100},
+		},
+	}
+
+	server.POST(routes.AccountBalances(":chainID"), handleAccountBalances).
+		SetSummary("Get the on-chain balances of several agents").
+		AddParamPath("", "chainID", "ChainID (base58-encoded)").
+		AddParamBody(exampleReq, "Request", "Agent IDs", true).
+		AddResponse(http.StatusOK, "Balances", exampleRes, nil).
+		AddResponse(http.StatusBadRequest, "Invalid or too many agent IDs", httperrors.BadRequest("Bad Request"), nil)
+}
+
+func handleAccountBalances(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID: %+v", c.Param("chainID")))
+	}
+
+	var req model.AccountBalancesRequest
+	if err := c.Bind(&req); err != nil {
+		return httperrors.BadRequest("Invalid request body")
+	}
+	agentIDs, err := coretypes.DecodeAgentIDs(req.AgentIDs.Bytes())
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid agent IDs: %v", err))
+	}
+	if len(agentIDs) > model.MaxAccountBalancesAgents {
+		return httperrors.BadRequest(fmt.Sprintf("Too many agent IDs: %d, the maximum is %d", len(agentIDs), model.MaxAccountBalancesAgents))
+	}
+
+	chain := chains.GetChain(chainID)
+	if chain == nil {
+		return httperrors.NotFound(fmt.Sprintf("Chain not found: %s", chainID))
+	}
+
+	vctx, err := viewcontext.NewFromDB(*chain.ID(), chain.Processors())
+	if err != nil {
+		return fmt.Errorf("Failed to create context: %v", err)
+	}
+
+	res := model.AccountBalancesResponse{Balances: make(map[model.AgentID]map[model.Color]int64)}
+	for i := range agentIDs {
+		ret, err := vctx.CallView(accounts.Interface.Hname(), coretypes.Hn(accounts.FuncBalance), dict.Dict{
+			accounts.ParamAgentID: codec.EncodeAgentID(agentIDs[i]),
+		})
+		if err != nil {
+			return fmt.Errorf("View call failed: %v", err)
+		}
+		balances, err := accounts.DecodeBalances(ret)
+		if err != nil {
+			return err
+		}
+		bals := make(map[model.Color]int64)
+		for color, amount := range balances {
+			bals[model.NewColor(&color)] = amount
+		}
+		res.Balances[model.NewAgentID(&agentIDs[i])] = bals
+	}
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,33 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountBalancesTooManyAgents(t *testing.T) {
+	agentIDs := make([]coretypes.AgentID, model.MaxAccountBalancesAgents+1)
+	for i := range agentIDs {
+		agentIDs[i] = coretypes.NewRandomAgentID()
+	}
+	body, err := json.Marshal(&model.AccountBalancesRequest{AgentIDs: model.NewBytes(coretypes.EncodeAgentIDs(agentIDs))})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+	c.SetParamNames("chainID")
+	c.SetParamValues(coretypes.NewRandomChainID().String())
+	he, ok := handleAccountBalances(c).(*httperrors.HTTPError)
+	require.True(t, ok)
+	require.Equal(t, http.StatusBadRequest, he.Code)
+}
@@ -16,7 +16,10 @@ import (
 	"github.com/pangpanglabs/echoswagger/v2"
 )
 
-func AddEndpoints(server echoswagger.ApiRouter) {
+// AddEndpoints adds the endpoints to access the state. Only the stateReaders are allowed
+// to query the state with signed requests. If there are stateReaders, the endpoints reading
+// the raw state without a signed request are disabled
+func AddEndpoints(server echoswagger.ApiRouter, stateReaders []coretypes.AgentID) {
 	dictExample := dict.Dict{
 		kv.Key("key1"): []byte("value1"),
 	}.JSONDict()
@@ -27,6 +30,10 @@ func AddEndpoints(server echoswagger.ApiRouter) {
 		AddParamPath("getInfo", "fname", "Function name").
 		AddParamBody(dictExample, "params", "Parameters", false).
 		AddResponse(http.StatusOK, "Result", dictExample, nil)
+
+	addStateQueryEndpoint(server, stateReaders)
+	addAccountBalancesEndpoint(server)
+	addStreamStateMapEndpoint(server, stateReaders)
 }
 
 func handleCallView(c echo.Context) error {
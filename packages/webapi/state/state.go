@@ -1,9 +1,8 @@
-// +build ignore
-
 // access to the solid state of the smart contract
 package state
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -18,53 +17,127 @@ import (
 	"github.com/pangpanglabs/echoswagger/v2"
 )
 
-func addStateQueryEndpoint(server echoswagger.ApiRouter) {
-	server.GET(routes.StateQuery(":chainID"), handleStateQuery).
-		SetDeprecated().
+func addStateQueryEndpoint(server echoswagger.ApiRouter, stateReaders []coretypes.AgentID) {
+	server.GET(routes.StateQuery(":chainID"), stateQueryHandler(stateReaders)).
 		SetSummary("Query the chain state").
+		SetDescription("Disabled when webapi.stateReaders is configured: use the signed state query").
 		AddParamPath("", "chainID", "ChainID (base58)").
 		AddParamBody(statequery.Request{}, "query", "Query parameters", true).
-		AddResponse(http.StatusOK, "Query result", statequery.Results{}, nil)
+		AddResponse(http.StatusOK, "Query result", statequery.Results{}, nil).
+		AddResponse(http.StatusForbidden, "Unsigned state queries are disabled", httperrors.Forbidden("Forbidden"), nil)
+
+	server.POST(routes.StateQuerySigned(":chainID"), signedStateQueryHandler(stateReaders)).
+		SetSummary("Query the chain state with a request signed by the reading agent").
+		SetDescription("Only the agents configured in webapi.stateReaders are allowed to read").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamBody(statequery.SignedRequest{}, "query", "Signed query parameters", true).
+		AddResponse(http.StatusOK, "Query result", statequery.Results{}, nil).
+		AddResponse(http.StatusUnauthorized, "Invalid or expired signature", httperrors.Unauthorized("Unauthorized"), nil).
+		AddResponse(http.StatusForbidden, "The signer is not allowed to read the state", httperrors.Forbidden("Forbidden"), nil)
 }
 
-func handleStateQuery(c echo.Context) error {
-	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
-	if err != nil {
-		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID: %+v", c.Param("chainID")))
+// maxSignedQueryAge is how long a signed state query is accepted after it was signed
+const maxSignedQueryAge = 1 * time.Minute
+
+// stateQueryHandler serves unsigned state queries, unless the readers are restricted
+func stateQueryHandler(readers []coretypes.AgentID) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if len(readers) > 0 {
+			return errUnsignedStateRead()
+		}
+		chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
+		if err != nil {
+			return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID: %+v", c.Param("chainID")))
+		}
+
+		var req statequery.Request
+		if err := c.Bind(&req); err != nil {
+			return httperrors.BadRequest("Failed parsing query request params")
+		}
+		return executeStateQuery(c, chainID, &req)
 	}
+}
 
-	var req statequery.Request
-	if err := c.Bind(&req); err != nil {
-		return httperrors.BadRequest("Failed parsing query request params")
+// errUnsignedStateRead is returned by the endpoints reading the raw state without a signed request
+// when the readers are restricted, otherwise they would serve the state to anyone
+func errUnsignedStateRead() error {
+	return httperrors.Forbidden("The state readers are restricted: use the signed state query")
+}
+
+// signedStateQueryHandler authenticates the signer of the query and authorizes it against the readers
+func signedStateQueryHandler(readers []coretypes.AgentID) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
+		if err != nil {
+			return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID: %+v", c.Param("chainID")))
+		}
+
+		var req statequery.SignedRequest
+		if err := c.Bind(&req); err != nil {
+			return httperrors.BadRequest("Failed parsing query request params")
+		}
+		signer, query, err := req.Verify(&chainID, maxSignedQueryAge)
+		if err != nil {
+			return httperrors.Unauthorized(err.Error())
+		}
+		if !isStateReader(readers, signer) {
+			return httperrors.Forbidden(fmt.Sprintf("%s is not allowed to read the state", signer.String()))
+		}
+		return executeStateQuery(c, chainID, query)
 	}
+}
 
+func isStateReader(readers []coretypes.AgentID, agentID coretypes.AgentID) bool {
+	for _, r := range readers {
+		if r == agentID {
+			return true
+		}
+	}
+	return false
+}
+
+func executeStateQuery(c echo.Context, chainID coretypes.ChainID, req *statequery.Request) error {
+	var err error
 	// TODO serialize access to solid state
-	state, batch, exist, err := state.LoadSolidState(&chainID)
+	var vs state.VirtualState
+	var batch state.Block
+	if req.StateIndex != nil {
+		vs, batch, err = state.LoadStateAtIndex(&chainID, *req.StateIndex)
+		if errors.Is(err, state.ErrStateIndexPruned) || errors.Is(err, state.ErrStateIndexNotReached) {
+			return httperrors.NotFound(fmt.Sprintf("State #%d not available for chain %s: %v", *req.StateIndex, chainID.String(), err))
+		}
+		if err != nil {
+			return err
+		}
+	} else {
+		var exist bool
+		vs, batch, exist, err = state.LoadSolidState(&chainID)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return httperrors.NotFound(fmt.Sprintf("State not found with address %s", chainID.String()))
+		}
+	}
+	// all key queries are executed on the same state, loaded once
+	results, err := req.Execute(vs.Variables())
+	if errors.Is(err, statequery.ErrZeroPageLimit) {
+		return httperrors.BadRequest(err.Error())
+	}
 	if err != nil {
 		return err
 	}
-	if !exist {
-		return httperrors.NotFound(fmt.Sprintf("State not found with address %s", chainID.String()))
-	}
 	txid := batch.StateTransactionID()
+	stateHash := vs.Hash()
 	ret := &statequery.Results{
-		KeyQueryResults: make([]*statequery.QueryResult, len(req.KeyQueries)),
+		KeyQueryResults: results,
 
-		StateIndex: state.BlockIndex(),
-		Timestamp:  time.Unix(0, state.Timestamp()),
-		StateHash:  state.Hash(),
+		StateIndex: vs.BlockIndex(),
+		Timestamp:  time.Unix(0, vs.Timestamp()),
+		StateHash:  &stateHash,
 		StateTxId:  model.NewValueTxID(&txid),
 		Requests:   make([]*coretypes.RequestID, len(batch.RequestIDs())),
 	}
 	copy(ret.Requests, batch.RequestIDs())
-	vars := state.Variables()
-	for i, q := range req.KeyQueries {
-		result, err := q.Execute(vars)
-		if err != nil {
-			return err
-		}
-		ret.KeyQueryResults[i] = result
-	}
-
 	return c.JSON(http.StatusOK, ret)
 }
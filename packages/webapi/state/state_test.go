@@ -0,0 +1,68 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model/statequery"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedStateQueryAuthorization(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	reader := signaturescheme.RandBLS()
+	handler := signedStateQueryHandler([]coretypes.AgentID{coretypes.NewAgentIDFromAddress(reader.Address())})
+
+	// query returns the HTTP status of the error returned by the handler
+	query := func(signed *statequery.SignedRequest) int {
+		body, err := json.Marshal(signed)
+		require.NoError(t, err)
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetParamNames("chainID")
+		c.SetParamValues(chainID.String())
+		he, ok := handler(c).(*httperrors.HTTPError)
+		require.True(t, ok)
+		return he.Code
+	}
+
+	signed, err := statequery.NewRequest().Sign(&chainID, signaturescheme.RandBLS())
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, query(signed))
+
+	// a valid signature for another chain is not accepted
+	otherChainID := coretypes.NewRandomChainID()
+	signed, err = statequery.NewRequest().Sign(&otherChainID, reader)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, query(signed))
+}
+
+func TestUnsignedStateReadsRestricted(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	readers := []coretypes.AgentID{coretypes.NewAgentIDFromAddress(signaturescheme.RandBLS().Address())}
+
+	// the unsigned endpoints would serve the state restricted to the readers to anyone
+	for name, handler := range map[string]echo.HandlerFunc{
+		"state query": stateQueryHandler(readers),
+		"stream map":  streamStateMapHandler(readers),
+	} {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", bytes.NewReader([]byte("{}")))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetParamNames("chainID", "contractID", "mapName")
+		c.SetParamValues(chainID.String(), coretypes.NewContractID(chainID, coretypes.Hn("tokenregistry")).Base58(), "m")
+		he, ok := handler(c).(*httperrors.HTTPError)
+		require.True(t, ok, name)
+		require.Equal(t, http.StatusForbidden, he.Code, name)
+	}
+}
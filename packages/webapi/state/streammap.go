@@ -0,0 +1,105 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+// contentTypeNDJSON is the content type of newline-delimited JSON
+const contentTypeNDJSON = "application/x-ndjson"
+
+func addStreamStateMapEndpoint(server echoswagger.ApiRouter, stateReaders []coretypes.AgentID) {
+	server.GET(routes.StreamStateMap(":contractID", ":mapName"), streamStateMapHandler(stateReaders)).
+		SetSummary("Stream all entries of a map in the state of a contract").
+		SetDescription("The entries are streamed as newline-delimited JSON, one model.MapEntry per line, "+
+			"without loading the whole map into memory. The last line is model.MapStreamEnd with the number of the entries: "+
+			"if the stream fails after the status is sent, the line is missing. "+
+			"Disabled when webapi.stateReaders is configured").
+		AddParamPath("", "contractID", "ContractID (base58-encoded)").
+		AddParamPath("", "mapName", "Name of the map in the state of the contract").
+		AddResponse(http.StatusOK, "Map entries", model.MapEntry{}, nil).
+		AddResponse(http.StatusNotFound, "The contract or the map does not exist", httperrors.NotFound("Not found"), nil).
+		AddResponse(http.StatusForbidden, "Unsigned state reads are disabled", httperrors.Forbidden("Forbidden"), nil)
+}
+
+// streamStateMapHandler streams the map, unless the readers are restricted
+func streamStateMapHandler(readers []coretypes.AgentID) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if len(readers) > 0 {
+			return errUnsignedStateRead()
+		}
+		return handleStreamStateMap(c)
+	}
+}
+
+func handleStreamStateMap(c echo.Context) error {
+	contractID, err := coretypes.NewContractIDFromBase58(c.Param("contractID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid contract ID: %+v", c.Param("contractID")))
+	}
+
+	chainID := contractID.ChainID()
+	virtualState, _, ok, err := state.LoadSolidState(&chainID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return httperrors.NotFound(fmt.Sprintf("State not found for contract %s", contractID.String()))
+	}
+	rootState := subrealm.New(virtualState.Variables(), kv.Key(root.Interface.Hname().Bytes()))
+	if _, err := root.FindContract(rootState, contractID.Hname()); err != nil {
+		if errors.Is(err, root.ErrContractNotFound) {
+			return httperrors.NotFound(fmt.Sprintf("Contract not found: %s", contractID.String()))
+		}
+		return err
+	}
+	m := collections.NewMapReadOnly(
+		subrealm.New(virtualState.Variables(), kv.Key(contractID.Hname().Bytes())),
+		c.Param("mapName"),
+	)
+	// an empty map is not distinguishable from a map which does not exist
+	n, err := m.Len()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return httperrors.NotFound(fmt.Sprintf("Map '%s' not found in contract %s", c.Param("mapName"), contractID.String()))
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, contentTypeNDJSON)
+	res.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(res)
+	var encodeErr error
+	var count uint32
+	err = m.Iterate(func(elemKey []byte, value []byte) bool {
+		if encodeErr = enc.Encode(&model.MapEntry{Key: model.NewBytes(elemKey), Value: model.NewBytes(value)}); encodeErr != nil {
+			return false
+		}
+		count++
+		res.Flush()
+		return true
+	})
+	// the status is already sent: the error only ends the stream, without the MapStreamEnd line
+	if err != nil {
+		return err
+	}
+	if encodeErr != nil {
+		return encodeErr
+	}
+	return enc.Encode(&model.MapStreamEnd{Entries: count})
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/iotaledger/hive.go/daemon"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/parameters"
 	"github.com/iotaledger/wasp/packages/util/auth"
 	"github.com/iotaledger/wasp/packages/webapi"
@@ -56,10 +57,11 @@ func configure(*node.Plugin) {
 	Server.Echo().Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
 		Format: `${time_rfc3339_nano} ${remote_ip} ${method} ${uri} ${status} error="${error}"` + "\n",
 	}))
+	Server.Echo().Use(middleware.Gzip())
 
 	auth.AddAuthentication(Server.Echo(), parameters.GetStringToString(parameters.WebAPIAuth))
 
-	webapi.Init(Server, adminWhitelist())
+	webapi.Init(Server, adminWhitelist(), stateReaders(), parameters.GetBool(parameters.WebAPIRequestTrace))
 }
 
 func customHTTPErrorHandler(err error, c echo.Context) {
@@ -84,6 +86,18 @@ func adminWhitelist() []net.IP {
 	return r
 }
 
+func stateReaders() []coretypes.AgentID {
+	r := make([]coretypes.AgentID, 0)
+	for _, s := range parameters.GetStringSlice(parameters.WebAPIStateReaders) {
+		agentID, err := coretypes.NewAgentIDFromString(s)
+		if err != nil {
+			log.Panicf("invalid agent ID in %s: '%s': %v", parameters.WebAPIStateReaders, s, err)
+		}
+		r = append(r, agentID)
+	}
+	return r
+}
+
 func run(_ *node.Plugin) {
 	log.Infof("Starting %s ...", PluginName)
 	if err := daemon.BackgroundWorker("WebAPI Server", worker, parameters.PriorityWebAPI); err != nil {